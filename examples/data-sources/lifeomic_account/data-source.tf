@@ -0,0 +1,13 @@
+data "lifeomic_account" "customer" {
+  id = "my-customer"
+}
+
+resource "lifeomic_policy" "readers" {
+  account_id = data.lifeomic_account.customer.id
+  name       = "readers"
+
+  rule {
+    operation = "readData"
+    allowed   = true
+  }
+}
@@ -0,0 +1,10 @@
+data "lifeomic_accounts" "all" {}
+
+variable "customer_account" {
+  type = string
+
+  validation {
+    condition     = contains(data.lifeomic_accounts.all.ids, var.customer_account)
+    error_message = "The provider's credentials can't access this account."
+  }
+}
@@ -0,0 +1,15 @@
+data "lifeomic_policies" "team" {
+  name_prefix = "team-"
+}
+
+locals {
+  managed_policies   = [for policy in lifeomic_policy.team : policy.name]
+  unmanaged_policies = setsubtract(data.lifeomic_policies.team.names, local.managed_policies)
+}
+
+check "team_policies_managed" {
+  assert {
+    condition     = length(local.unmanaged_policies) == 0
+    error_message = "Policies not managed by Terraform: ${join(", ", local.unmanaged_policies)}"
+  }
+}
@@ -0,0 +1,18 @@
+data "lifeomic_policy_document" "readers" {
+  source_policy_documents = [lifeomic_policy.base.policy_json]
+
+  rule {
+    operation = "readData"
+
+    comparison {
+      subject = "user.groups"
+      type    = "includes"
+      value   = "readers"
+    }
+  }
+}
+
+resource "lifeomic_policy" "readers" {
+  name        = "readers"
+  policy_json = data.lifeomic_policy_document.readers.json
+}
@@ -0,0 +1,17 @@
+data "lifeomic_policy_test" "readers" {
+  policy_json = data.lifeomic_policy_document.readers.json
+
+  case {
+    name      = "readers can read data"
+    operation = "readData"
+    user      = { groups = ["readers"] }
+    allowed   = true
+  }
+
+  case {
+    name      = "readers can't write data"
+    operation = "writeData"
+    user      = { groups = ["readers"] }
+    allowed   = false
+  }
+}
@@ -25,9 +25,10 @@ const (
 
 // Account represents a PHC Account.
 type Account struct {
-	ID    string `json:"id"`
-	Name  string `json:"name"`
-	Owner string `json:"owner"`
+	ID    string      `json:"id"`
+	Name  string      `json:"name"`
+	Owner string      `json:"owner"`
+	Type  AccountType `json:"type"`
 }
 
 type accountService struct {
@@ -42,8 +43,9 @@ type accountListResponse struct {
 }
 
 func (s *accountService) List(ctx context.Context) ([]Account, error) {
-	req := s.Request(ctx).SetResult(&accountListResponse{})
-	req.Header.Del(accountHeader)
+	// Listing accounts isn't scoped to an account, so the request is sent
+	// without the LifeOmic-Account header.
+	req := s.Request(withoutAccount(ctx)).SetResult(&accountListResponse{})
 
 	res, err := checkResponse(req.Get("/accounts"))
 	if err != nil {
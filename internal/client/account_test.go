@@ -0,0 +1,52 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountService_List(t *testing.T) {
+	t.Setenv(UseLambdaEnvVar, "")
+
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		if r.URL.Path != "/accounts" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"accounts": [
+			{"id": "my-account", "name": "My Account", "owner": "johndoe", "type": "enterprise"},
+			{"id": "sandbox", "name": "Sandbox", "owner": "janedoe", "type": "free"}
+		]}`)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := New(Config{
+		AccountID:   "my-account",
+		AuthToken:   "my-token",
+		ServiceName: "account-service",
+		Endpoints:   map[string]string{"account-service": server.URL},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	accounts, err := client.Accounts().List(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []Account{
+		{ID: "my-account", Name: "My Account", Owner: "johndoe", Type: AccountTypeEnterprise},
+		{ID: "sandbox", Name: "Sandbox", Owner: "janedoe", Type: AccountTypeFree},
+	}, accounts)
+	assert.Empty(t, header.Get(accountHeader), "should list accounts without the account header")
+	assert.Equal(t, "Bearer my-token", header.Get("Authorization"))
+}
@@ -0,0 +1,195 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// tokenExpiryDelta is how long before their expiry tokens are refreshed,
+	// so that they don't expire while a request is in flight.
+	tokenExpiryDelta = time.Minute
+
+	defaultTokenRequestTimeout = 30 * time.Second
+)
+
+// Token is an access token for authenticating with the PHC API.
+type Token struct {
+	AccessToken string
+	// Expiry is when the token expires. The zero value means the token
+	// doesn't expire.
+	Expiry time.Time
+}
+
+// Valid reports whether the token is set and isn't about to expire.
+func (t *Token) Valid() bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	return t.Expiry.IsZero() || time.Now().Add(tokenExpiryDelta).Before(t.Expiry)
+}
+
+// TokenSource supplies the access tokens AuthedTransport authenticates
+// requests with.
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// CachingTokenSource caches the tokens of another TokenSource until they're
+// about to expire. It's safe for concurrent use.
+type CachingTokenSource struct {
+	source TokenSource
+
+	mu    sync.Mutex
+	token *Token
+}
+
+// NewCachingTokenSource creates a CachingTokenSource wrapping source.
+func NewCachingTokenSource(source TokenSource) *CachingTokenSource {
+	return &CachingTokenSource{source: source}
+}
+
+// Token returns the cached token if it's still valid, and otherwise gets a
+// new one from the wrapped TokenSource.
+func (s *CachingTokenSource) Token(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token.Valid() {
+		return s.token, nil
+	}
+
+	token, err := s.source.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.token = token
+	return token, nil
+}
+
+// Invalidate discards the cached token, e.g. after the API rejected it, so
+// that the next call to Token gets a new one.
+func (s *CachingTokenSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = nil
+}
+
+// OAuthConfig configures obtaining access tokens from an OAuth 2.0 token
+// endpoint.
+type OAuthConfig struct {
+	// TokenURL is the URL of the token endpoint.
+	TokenURL string
+
+	ClientID     string
+	ClientSecret string
+
+	// RefreshToken selects the refresh_token grant. Otherwise, the
+	// client_credentials grant is used, which requires ClientSecret.
+	RefreshToken string
+
+	Scopes []string
+}
+
+// oauthTokenSource implements TokenSource by requesting tokens from an OAuth
+// 2.0 token endpoint. It isn't safe for concurrent use, so it should be
+// wrapped with a CachingTokenSource.
+type oauthTokenSource struct {
+	config     OAuthConfig
+	httpClient *http.Client
+}
+
+// NewOAuthTokenSource creates a TokenSource exchanging client credentials or
+// a refresh token for access tokens. Tokens are requested with httpClient,
+// or a default client if it's nil, and time out after 30 seconds if
+// httpClient has no timeout. When using a refresh token, rotated refresh
+// tokens are used for subsequent requests.
+func NewOAuthTokenSource(config OAuthConfig, httpClient *http.Client) TokenSource {
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+	if httpClient.Timeout == 0 {
+		withTimeout := *httpClient
+		withTimeout.Timeout = defaultTokenRequestTimeout
+		httpClient = &withTimeout
+	}
+	return &oauthTokenSource{config: config, httpClient: httpClient}
+}
+
+// tokenResponse is the response of an OAuth 2.0 token endpoint.
+// See: https://www.rfc-editor.org/rfc/rfc6749#section-5
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func (s *oauthTokenSource) Token(ctx context.Context) (*Token, error) {
+	form := url.Values{}
+	if s.config.RefreshToken != "" {
+		form.Set("grant_type", "refresh_token")
+		form.Set("refresh_token", s.config.RefreshToken)
+	} else {
+		form.Set("grant_type", "client_credentials")
+	}
+	if len(s.config.Scopes) != 0 {
+		form.Set("scope", strings.Join(s.config.Scopes, " "))
+	}
+	if s.config.ClientSecret == "" {
+		// Public clients identify themselves in the request body.
+		form.Set("client_id", s.config.ClientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if s.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(s.config.ClientID), url.QueryEscape(s.config.ClientSecret))
+	}
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token response: %w", err)
+	}
+
+	var tokenRes tokenResponse
+	if err := json.Unmarshal(body, &tokenRes); err != nil {
+		return nil, fmt.Errorf("token request failed with status %d: malformed response: %w", res.StatusCode, err)
+	}
+	if tokenRes.Error != "" {
+		return nil, fmt.Errorf("token request failed with status %d: %s: %s", res.StatusCode, tokenRes.Error, tokenRes.ErrorDescription)
+	}
+	if res.StatusCode != http.StatusOK || tokenRes.AccessToken == "" {
+		return nil, fmt.Errorf("token request failed with status %d: response has no access token", res.StatusCode)
+	}
+
+	if s.config.RefreshToken != "" && tokenRes.RefreshToken != "" {
+		s.config.RefreshToken = tokenRes.RefreshToken
+	}
+
+	token := &Token{AccessToken: tokenRes.AccessToken}
+	if tokenRes.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(tokenRes.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
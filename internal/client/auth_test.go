@@ -0,0 +1,194 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTokenServer starts a local OAuth token endpoint which issues
+// sequentially numbered tokens, and rotates refresh tokens the same way.
+func newTokenServer(t *testing.T, expiresIn int64) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var issued int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		clientID, clientSecret, ok := r.BasicAuth()
+		switch r.PostForm.Get("grant_type") {
+		case "client_credentials":
+			if !ok || clientID != "my-client" || clientSecret != "my-secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprint(w, `{"error": "invalid_client", "error_description": "bad client credentials"}`)
+				return
+			}
+		case "refresh_token":
+			if !ok {
+				clientID = r.PostForm.Get("client_id")
+			}
+			if clientID != "my-client" || !strings.HasPrefix(r.PostForm.Get("refresh_token"), "refresh-") {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error": "invalid_grant", "error_description": "bad refresh token"}`)
+				return
+			}
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error": "unsupported_grant_type"}`)
+			return
+		}
+
+		n := atomic.AddInt32(&issued, 1)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token":  fmt.Sprintf("token-%d", n),
+			"token_type":    "Bearer",
+			"expires_in":    expiresIn,
+			"refresh_token": fmt.Sprintf("refresh-%d", n),
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &issued
+}
+
+func TestOAuthTokenSource(t *testing.T) {
+	server, _ := newTokenServer(t, 3600)
+
+	for _, fixture := range []struct {
+		name          string
+		config        OAuthConfig
+		expectedValue []string
+		expectedErr   string
+	}{
+		{
+			name:          "should exchange client credentials",
+			config:        OAuthConfig{ClientID: "my-client", ClientSecret: "my-secret"},
+			expectedValue: []string{"token-1", "token-2"},
+		},
+		{
+			name:          "should exchange and rotate refresh tokens",
+			config:        OAuthConfig{ClientID: "my-client", RefreshToken: "refresh-0"},
+			expectedValue: []string{"token-3", "token-4"},
+		},
+		{
+			name:        "should return token endpoint errors",
+			config:      OAuthConfig{ClientID: "my-client", ClientSecret: "wrong"},
+			expectedErr: "token request failed with status 401: invalid_client: bad client credentials",
+		},
+		{
+			name:        "should reject invalid refresh tokens",
+			config:      OAuthConfig{ClientID: "my-client", RefreshToken: "revoked"},
+			expectedErr: "invalid_grant: bad refresh token",
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			fixture.config.TokenURL = server.URL
+			source := NewOAuthTokenSource(fixture.config, nil)
+
+			var values []string
+			for i := 0; i < 2; i++ {
+				token, err := source.Token(context.Background())
+				if err != nil {
+					if fixture.expectedErr == "" {
+						t.Errorf("unexpected error: %s", err)
+						return
+					}
+					assert.ErrorContains(t, err, fixture.expectedErr)
+					return
+				}
+				assert.True(t, token.Valid())
+				values = append(values, token.AccessToken)
+			}
+
+			if fixture.expectedErr != "" {
+				t.Errorf("expected error matching: %s; got nil", fixture.expectedErr)
+				return
+			}
+			assert.Equal(t, fixture.expectedValue, values)
+		})
+	}
+}
+
+func TestAuthedTransport_TokenSource(t *testing.T) {
+	for _, fixture := range []struct {
+		name               string
+		expiresIn          int64
+		rejectedTokens     map[string]bool
+		expectedTokens     []string
+		expectedStatusCode int
+	}{
+		{
+			name:               "should reuse valid tokens",
+			expiresIn:          3600,
+			expectedTokens:     []string{"token-1", "token-1", "token-1"},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "should refresh tokens before they expire",
+			expiresIn:          30,
+			expectedTokens:     []string{"token-1", "token-2", "token-3"},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "should refresh tokens on unauthorized responses",
+			expiresIn:          3600,
+			rejectedTokens:     map[string]bool{"token-1": true},
+			expectedTokens:     []string{"token-1", "token-2", "token-2", "token-2"},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "should only retry unauthorized responses once",
+			expiresIn:          3600,
+			rejectedTokens:     map[string]bool{"token-1": true, "token-2": true, "token-3": true, "token-4": true},
+			expectedTokens:     []string{"token-1", "token-2", "token-2", "token-3", "token-3", "token-4"},
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			tokenServer, _ := newTokenServer(t, fixture.expiresIn)
+
+			var tokens []string
+			apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+				tokens = append(tokens, token)
+				if fixture.rejectedTokens[token] {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			t.Cleanup(apiServer.Close)
+
+			transport := &AuthedTransport{
+				TokenSource: NewCachingTokenSource(NewOAuthTokenSource(OAuthConfig{
+					TokenURL:     tokenServer.URL,
+					ClientID:     "my-client",
+					ClientSecret: "my-secret",
+				}, nil)),
+			}
+			httpClient := &http.Client{Transport: transport}
+
+			var statusCode int
+			for i := 0; i < 3; i++ {
+				res, err := httpClient.Post(apiServer.URL, "application/json", strings.NewReader(`{}`))
+				if err != nil {
+					t.Fatal(err)
+				}
+				res.Body.Close()
+				statusCode = res.StatusCode
+			}
+
+			assert.Equal(t, fixture.expectedTokens, tokens)
+			assert.Equal(t, fixture.expectedStatusCode, statusCode)
+		})
+	}
+}
@@ -5,10 +5,10 @@ import (
 	"fmt"
 	"net/http"
 	"os"
-	"strconv"
 	"time"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/lambda"
 )
 
 // Git attributes are set at build-time (via LDFlags) so that it's accessible
@@ -24,15 +24,17 @@ const (
 	defaultAPIVersion = "v1"
 	defaultHost       = "api.us.lifeomic.com"
 
-	defaultMaxRetries       = 3
-	defaultRetryMaxWaitTime = time.Second
-
 	accountHeader = "LifeOmic-Account"
+	userHeader    = "LifeOmic-User"
+	policyHeader  = "LifeOmic-Policy"
 
 	AuthTokenEnvVar = "LIFEOMIC_TOKEN"
 	HostEnvVar      = "LIFEOMIC_HOST"
 	AccountIDEnvVar = "LIFEOMIC_ACCOUNT"
 	DebugEnvVar     = "LIFEOMIC_DEBUG"
+	UseLambdaEnvVar = "LIFEOMIC_USE_LAMBDA"
+	UserIDEnvVar    = "LIFEOMIC_USER"
+	PolicyEnvVar    = "LIFEOMIC_POLICY"
 )
 
 type Interface interface {
@@ -44,18 +46,70 @@ type Interface interface {
 type Config struct {
 	APIVersion string
 	Host       string
+	// Region is the named PHC region to use if Host isn't set. See
+	// Regions.
+	Region string
+	// Endpoints maps service names to the URLs of their endpoints, overriding
+	// the URLs derived from the host.
+	Endpoints map[string]string
 
 	AccountID string
 	AuthToken string
-	Header    map[string]string
-
-	MaxRetries       int
+	// TokenSource supplies access tokens, taking precedence over AuthToken.
+	TokenSource TokenSource
+	Header      map[string]string
+
+	// UserID is sent in the LifeOmic-User header to make requests on behalf
+	// of the user. It defaults to $LIFEOMIC_USER.
+	UserID string
+	// Policy is sent in the LifeOmic-Policy header to grant requests the
+	// permissions it describes. It defaults to the JSON encoded policy in
+	// $LIFEOMIC_POLICY.
+	Policy *PolicyDocument
+
+	// MaxRetries is the number of times idempotent requests are retried
+	// after network errors, 429s, and 5xx responses. It defaults to 3, and
+	// a negative value disables retries.
+	MaxRetries int
+	// RetryWaitTime and MaxRetryWaitTime bound the exponential backoff
+	// between retries. See RetryPolicy.
+	RetryWaitTime    time.Duration
 	MaxRetryWaitTime time.Duration
 
+	// Limiters maps service names to the Limiters their requests wait for.
+	// The Limiters are shared by every client of the service created from
+	// the config, including the clients of other accounts.
+	Limiters map[string]*Limiter
+
+	// Debug logs the bodies of requests and responses, with sensitive values
+	// masked, at the TRACE level. It defaults to $LIFEOMIC_DEBUG. See
+	// LoggingTransport.
 	Debug bool
 
 	ServiceName string
-	Transport   http.RoundTripper
+	// Transport sends requests over HTTP, e.g. an http.Transport from
+	// NewHTTPTransport. It defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+	// BaseTransports caches the transports clients created from the config
+	// send requests with, so that they're shared by the clients of every
+	// account. Configs which don't set it create new transports for each
+	// client.
+	BaseTransports *BaseTransports
+	// Timeout bounds how long each attempt of a request may take, including
+	// reading the response. Zero disables the timeout.
+	Timeout time.Duration
+
+	// UseLambda invokes the service's lambda function directly instead of
+	// sending requests through the API gateway. If it's nil, it defaults to
+	// $LIFEOMIC_USE_LAMBDA.
+	UseLambda *bool
+	// LambdaQualifier is the function version or alias to invoke when
+	// LambdaFunctions doesn't specify one.
+	LambdaQualifier string
+	// LambdaFunctions maps service names to the lambda functions invoked for
+	// them. Services which aren't mapped invoke the function of the same
+	// name.
+	LambdaFunctions map[string]lambda.URI
 }
 
 // Client interfaces with the PHC API.
@@ -68,49 +122,38 @@ type Client struct {
 	policies PolicyService
 }
 
-type APIError struct {
-	Message string `json:"error"`
-}
-
-func (e APIError) Error() string { return e.Message }
-
 // New creates a new Client with the given Config.
-func New(config Config) *Client {
-	if config.AuthToken == "" {
+func New(config Config) (*Client, error) {
+	if config.AuthToken == "" && config.TokenSource == nil {
 		config.AuthToken = os.Getenv(AuthTokenEnvVar)
 	}
 	if config.AccountID == "" {
 		config.AccountID = os.Getenv(AccountIDEnvVar)
 	}
-	if config.Host == "" {
-		config.Host = defaultStr(os.Getenv(HostEnvVar), defaultHost)
+	host, err := config.ResolveHost()
+	if err != nil {
+		return nil, err
 	}
+	config.Host = host
 	if config.APIVersion == "" {
 		config.APIVersion = defaultAPIVersion
 	}
-	if config.MaxRetries == 0 {
-		config.MaxRetries = defaultMaxRetries
-	}
-	if config.MaxRetryWaitTime == 0 {
-		config.MaxRetryWaitTime = defaultRetryMaxWaitTime
-	}
 	if config.Header == nil {
 		config.Header = map[string]string{}
 	}
 
-	if !config.Debug {
-		// Treat any malformed value as false.
-		config.Debug, _ = strconv.ParseBool(os.Getenv(DebugEnvVar))
+	transport, err := NewAuthedTransport(config)
+	if err != nil {
+		return nil, err
 	}
 
-	transport := NewAuthedTransport(config.AuthToken, config.AccountID, config.ServiceName, config.Header)
 	httpClient := &http.Client{Transport: transport}
 	client := &Client{httpClient: resty.NewWithClient(httpClient), config: &config}
 	client.transport = transport
+	client.accounts = &accountService{Client: client}
 	client.policies = &policyService{Client: client}
-	client.httpClient.SetDebug(config.Debug)
 	client.init()
-	return client
+	return client, nil
 }
 
 // Client implements interface.
@@ -132,16 +175,18 @@ func (c *Client) SetUserAgent(userAgent string) {
 }
 
 // SetAPIVersion updates the baseURL of the underlying http client to use the
-// given API version.
+// given API version. It has no effect if the service's endpoint is set by
+// Config.Endpoints.
 func (c *Client) SetAPIVersion(version string) {
 	c.config.APIVersion = version
 	c.setBaseURL()
 }
 
 // SetAuthToken updates the Authorization header on the underlying http client
-// to the given value.
+// to the given value, replacing any TokenSource.
 func (c *Client) SetAuthToken(token string) {
 	c.transport.AuthToken = token
+	c.transport.TokenSource = nil
 }
 
 // SetAccount updates the client to send a LifeOmic-Account header with the
@@ -158,21 +203,23 @@ func (c *Client) init() {
 	c.httpClient.SetHeader("Content-Type", "application/json")
 	c.httpClient.SetHeader("Accept", "application/json")
 	c.SetUserAgent(fmt.Sprintf("%s%s %s", userAgentPrefix, GitRef, GitCommit))
-
-	// Configure retries.
-	c.httpClient.SetRetryCount(c.config.MaxRetries)
-	c.httpClient.SetRetryMaxWaitTime(c.config.MaxRetryWaitTime)
 }
 
 // Request creates a new HTTP request object.
 func (c *Client) Request(ctx context.Context) *resty.Request {
-	return c.httpClient.NewRequest().SetContext(ctx).SetError(&APIError{})
+	return c.httpClient.NewRequest().SetContext(ctx)
 }
 
 func (c *Client) setBaseURL() {
-	c.httpClient.SetBaseURL(fmt.Sprintf("https://%s/%s", c.config.Host, c.config.APIVersion))
+	baseURL, ok := c.config.Endpoints[c.config.ServiceName]
+	if !ok {
+		baseURL = serviceURL(c.config.Host, apiSubdomain, "/"+c.config.APIVersion)
+	}
+	c.httpClient.SetBaseURL(baseURL)
 }
 
+// checkResponse returns an APIError if the API responded with an error
+// status, regardless of whether the response body could be decoded.
 func checkResponse(res *resty.Response, err error) (*resty.Response, error) {
 	if err != nil {
 		// There was an error making the request.
@@ -180,18 +227,8 @@ func checkResponse(res *resty.Response, err error) (*resty.Response, error) {
 	}
 
 	if res.IsError() {
-		if apiErr, ok := res.Error().(*APIError); ok {
-			// The API responded with an error.
-			return res, apiErr
-		}
+		return res, NewAPIError(res.RawResponse, res.Body())
 	}
 
 	return res, nil
 }
-
-func defaultStr(val, defaultVal string) string {
-	if val == "" {
-		return defaultVal
-	}
-	return val
-}
@@ -0,0 +1,225 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ProfileEnvVar           = "LIFEOMIC_PROFILE"
+	ConfigFileEnvVar        = "LIFEOMIC_CONFIG_FILE"
+	TokenFileEnvVar         = "LIFEOMIC_TOKEN_FILE"
+	CredentialProcessEnvVar = "LIFEOMIC_CREDENTIAL_PROCESS"
+
+	DefaultProfile = "default"
+
+	defaultCredentialProcessTimeout = time.Minute
+)
+
+// ErrProfileNotFound is returned by LoadProfile if the config file doesn't
+// define the profile.
+var ErrProfileNotFound = errors.New("profile not found")
+
+// Profile is a named set of settings in a LifeOmic config file, e.g.
+//
+//	profiles:
+//	  default:
+//	    account: my-account
+//	    credential_process: lifeomic-login --json
+//	  eu:
+//	    account: my-eu-account
+//	    region: eu
+//	    token_file: ~/.lifeomic/eu-token
+type Profile struct {
+	Account string `yaml:"account"`
+	Host    string `yaml:"host"`
+	Region  string `yaml:"region"`
+
+	// Only one of Token, TokenFile, and CredentialProcess should be set.
+	Token             string `yaml:"token"`
+	TokenFile         string `yaml:"token_file"`
+	CredentialProcess string `yaml:"credential_process"`
+}
+
+// configFile is the format of the LifeOmic config file.
+type configFile struct {
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+// DefaultConfigFile returns the default path of the LifeOmic config file,
+// ~/.lifeomic/config.yaml.
+func DefaultConfigFile() (string, error) {
+	return ExpandPath("~/.lifeomic/config.yaml")
+}
+
+// LoadProfile reads the named profile from the config file at path. Relative
+// token file paths are resolved against the config file's directory.
+func LoadProfile(path, name string) (*Profile, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var config configFile
+	if err := yaml.Unmarshal(contents, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	profile, ok := config.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q isn't defined in %s", ErrProfileNotFound, name, path)
+	}
+
+	if profile.TokenFile != "" {
+		tokenFile, err := ExpandPath(profile.TokenFile)
+		if err != nil {
+			return nil, err
+		}
+		if !filepath.IsAbs(tokenFile) {
+			tokenFile = filepath.Join(filepath.Dir(path), tokenFile)
+		}
+		profile.TokenFile = tokenFile
+	}
+	return &profile, nil
+}
+
+// TokenSource returns a TokenSource for the profile's credentials, or nil if
+// it has none.
+func (p *Profile) TokenSource() TokenSource {
+	switch {
+	case p.Token != "":
+		return StaticTokenSource(p.Token)
+	case p.TokenFile != "":
+		return NewCachingTokenSource(NewFileTokenSource(p.TokenFile))
+	case p.CredentialProcess != "":
+		return NewCachingTokenSource(NewCommandTokenSource(p.CredentialProcess))
+	}
+	return nil
+}
+
+// ExpandPath replaces a leading ~ in path with the user's home directory.
+func ExpandPath(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to expand %s: %w", path, err)
+	}
+	return filepath.Join(home, path[1:]), nil
+}
+
+// StaticTokenSource returns a TokenSource which always returns token.
+func StaticTokenSource(token string) TokenSource {
+	return staticTokenSource{token: &Token{AccessToken: token}}
+}
+
+type staticTokenSource struct {
+	token *Token
+}
+
+func (s staticTokenSource) Token(context.Context) (*Token, error) {
+	return s.token, nil
+}
+
+// fileTokenSource implements TokenSource by reading a token from a file.
+type fileTokenSource struct {
+	path string
+}
+
+// NewFileTokenSource creates a TokenSource reading the token from the file at
+// path each time it's called, so that it picks up tokens rotated by other
+// processes. It should be wrapped with a CachingTokenSource, which only
+// rereads the file after the API rejects the token.
+func NewFileTokenSource(path string) TokenSource {
+	return &fileTokenSource{path: path}
+}
+
+func (s *fileTokenSource) Token(context.Context) (*Token, error) {
+	contents, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	token := strings.TrimSpace(string(contents))
+	if token == "" {
+		return nil, fmt.Errorf("token file %s is empty", s.path)
+	}
+	return &Token{AccessToken: token}, nil
+}
+
+// commandTokenSource implements TokenSource by running a credential process.
+type commandTokenSource struct {
+	command string
+}
+
+// credentialProcessOutput is the JSON output of a credential process.
+type credentialProcessOutput struct {
+	Token      string     `json:"token"`
+	Expiration *time.Time `json:"expiration"`
+}
+
+// NewCommandTokenSource creates a TokenSource which runs command with the
+// system shell and reads the token from its output. The output is either the
+// token itself, or a JSON object such as
+//
+//	{"token": "...", "expiration": "2022-08-01T12:00:00Z"}
+//
+// It should be wrapped with a CachingTokenSource so that the command only
+// runs again once the token is about to expire.
+func NewCommandTokenSource(command string) TokenSource {
+	return &commandTokenSource{command: command}
+}
+
+func (s *commandTokenSource) Token(ctx context.Context) (*Token, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultCredentialProcessTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", s.command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", s.command)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("credential process failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	output := bytes.TrimSpace(stdout.Bytes())
+	if !bytes.HasPrefix(output, []byte("{")) {
+		if len(output) == 0 {
+			return nil, errors.New("credential process returned no token")
+		}
+		return &Token{AccessToken: string(output)}, nil
+	}
+
+	var parsed credentialProcessOutput
+	if err := json.Unmarshal(output, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse credential process output: %w", err)
+	}
+	if parsed.Token == "" {
+		return nil, errors.New("credential process returned no token")
+	}
+
+	token := &Token{AccessToken: parsed.Token}
+	if parsed.Expiration != nil {
+		token.Expiry = *parsed.Expiration
+	}
+	return token, nil
+}
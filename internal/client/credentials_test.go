@@ -0,0 +1,187 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testConfigFile = `profiles:
+  default:
+    account: my-account
+    token: my-token
+  eu:
+    account: my-eu-account
+    region: eu
+    token_file: eu-token
+  process:
+    credential_process: echo my-process-token
+`
+
+func TestLoadProfile(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte(testConfigFile), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, fixture := range []struct {
+		name          string
+		profile       string
+		expectedValue *Profile
+		expectedErr   string
+	}{
+		{
+			name:          "should load profiles",
+			profile:       "default",
+			expectedValue: &Profile{Account: "my-account", Token: "my-token"},
+		},
+		{
+			name:          "should resolve token files relative to the config file",
+			profile:       "eu",
+			expectedValue: &Profile{Account: "my-eu-account", Region: "eu", TokenFile: filepath.Join(dir, "eu-token")},
+		},
+		{
+			name:        "should reject unknown profiles",
+			profile:     "staging",
+			expectedErr: `profile not found: "staging" isn't defined in`,
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			profile, err := LoadProfile(configPath, fixture.profile)
+			if err != nil {
+				if fixture.expectedErr == "" {
+					t.Errorf("unexpected error: %s", err)
+					return
+				}
+				assert.ErrorContains(t, err, fixture.expectedErr)
+				return
+			} else if fixture.expectedErr != "" {
+				t.Errorf("expected error matching: %s; got nil", fixture.expectedErr)
+				return
+			}
+
+			assert.Equal(t, fixture.expectedValue, profile)
+		})
+	}
+}
+
+func TestProfile_TokenSource(t *testing.T) {
+	dir := t.TempDir()
+	tokenPath := filepath.Join(dir, "token")
+	if err := os.WriteFile(tokenPath, []byte("my-file-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, fixture := range []struct {
+		name          string
+		profile       Profile
+		expectedValue string
+	}{
+		{name: "should use tokens", profile: Profile{Token: "my-token"}, expectedValue: "my-token"},
+		{name: "should read token files", profile: Profile{TokenFile: tokenPath}, expectedValue: "my-file-token"},
+		{name: "should run credential processes", profile: Profile{CredentialProcess: "echo my-process-token"}, expectedValue: "my-process-token"},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			token, err := fixture.profile.TokenSource().Token(context.Background())
+			if assert.NoError(t, err) {
+				assert.Equal(t, fixture.expectedValue, token.AccessToken)
+			}
+		})
+	}
+
+	assert.Nil(t, (&Profile{Account: "my-account"}).TokenSource())
+}
+
+func TestCommandTokenSource(t *testing.T) {
+	expiration := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	for _, fixture := range []struct {
+		name           string
+		command        string
+		expectedValue  string
+		expectedExpiry time.Time
+		expectedErr    string
+	}{
+		{
+			name:           "should parse JSON output",
+			command:        fmt.Sprintf(`echo '{"token": "my-token", "expiration": "%s"}'`, expiration.Format(time.RFC3339)),
+			expectedValue:  "my-token",
+			expectedExpiry: expiration,
+		},
+		{
+			name:          "should use plain output as the token",
+			command:       "printf '  my-token\n'",
+			expectedValue: "my-token",
+		},
+		{
+			name:        "should return command failures",
+			command:     "echo 'not logged in' >&2; exit 1",
+			expectedErr: "credential process failed: exit status 1: not logged in",
+		},
+		{
+			name:        "should reject empty output",
+			command:     "true",
+			expectedErr: "credential process returned no token",
+		},
+		{
+			name:        "should reject malformed JSON",
+			command:     `echo '{"token": '`,
+			expectedErr: "failed to parse credential process output",
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			token, err := NewCommandTokenSource(fixture.command).Token(context.Background())
+			if err != nil {
+				if fixture.expectedErr == "" {
+					t.Errorf("unexpected error: %s", err)
+					return
+				}
+				assert.ErrorContains(t, err, fixture.expectedErr)
+				return
+			} else if fixture.expectedErr != "" {
+				t.Errorf("expected error matching: %s; got nil", fixture.expectedErr)
+				return
+			}
+
+			assert.Equal(t, fixture.expectedValue, token.AccessToken)
+			assert.True(t, fixture.expectedExpiry.Equal(token.Expiry))
+		})
+	}
+}
+
+func TestCommandTokenSource_cache(t *testing.T) {
+	for _, fixture := range []struct {
+		name         string
+		expiresIn    time.Duration
+		expectedRuns int
+	}{
+		{name: "should cache tokens until they expire", expiresIn: time.Hour, expectedRuns: 1},
+		{name: "should rerun the process for expiring tokens", expiresIn: time.Second, expectedRuns: 3},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			runsPath := filepath.Join(t.TempDir(), "runs")
+			expiration := time.Now().Add(fixture.expiresIn).UTC().Format(time.RFC3339)
+			source := NewCachingTokenSource(NewCommandTokenSource(
+				fmt.Sprintf(`echo run >> %s; echo '{"token": "my-token", "expiration": "%s"}'`, runsPath, expiration)))
+
+			for i := 0; i < 3; i++ {
+				if _, err := source.Token(context.Background()); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			runs, err := os.ReadFile(runsPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, fixture.expectedRuns, strings.Count(string(runs), "run"))
+		})
+	}
+}
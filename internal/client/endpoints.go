@@ -0,0 +1,94 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	RegionEnvVar = "LIFEOMIC_REGION"
+
+	// apiSubdomain is the subdomain of the PHC API host.
+	apiSubdomain = "api"
+)
+
+// regionDomains maps the named PHC regions to their domains. Each service is
+// served from a subdomain of its region's domain, e.g. api.us.lifeomic.com.
+var regionDomains = map[string]string{
+	"us": "us.lifeomic.com",
+	"eu": "eu.lifeomic.com",
+}
+
+// Regions returns the names of the PHC regions, sorted.
+func Regions() []string {
+	regions := make([]string, 0, len(regionDomains))
+	for region := range regionDomains {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// HostForRegion returns the PHC API host of the named region.
+func HostForRegion(region string) (string, error) {
+	domain, ok := regionDomains[region]
+	if !ok {
+		return "", fmt.Errorf("unknown region %q, expected one of %s", region, strings.Join(Regions(), ", "))
+	}
+	return apiSubdomain + "." + domain, nil
+}
+
+// ResolveHost returns the PHC API host to use. Host takes precedence over
+// Region, and both take precedence over $LIFEOMIC_HOST and $LIFEOMIC_REGION.
+// The host of the us region is used if none are set.
+func (c Config) ResolveHost() (string, error) {
+	if c.Host != "" {
+		return c.Host, nil
+	}
+	if c.Region != "" {
+		return HostForRegion(c.Region)
+	}
+	if host := os.Getenv(HostEnvVar); host != "" {
+		return host, nil
+	}
+	if region := os.Getenv(RegionEnvVar); region != "" {
+		return HostForRegion(region)
+	}
+	return defaultHost, nil
+}
+
+// ServiceURL returns the URL of config.ServiceName's endpoint at path.
+//
+// The URL is taken from Endpoints if the service is listed there. Otherwise,
+// it's derived from the resolved host by replacing its leading "api" label with
+// the service's subdomain, e.g. the marketplace subdomain of
+// api.eu.lifeomic.com is marketplace.eu.lifeomic.com. Hosts without an "api"
+// label, such as a local proxy, serve every service. Hosts may include a
+// scheme, which defaults to https.
+func (c Config) ServiceURL(subdomain, path string) (string, error) {
+	if endpoint, ok := c.Endpoints[c.ServiceName]; ok {
+		return endpoint, nil
+	}
+
+	host, err := c.ResolveHost()
+	if err != nil {
+		return "", err
+	}
+	return serviceURL(host, subdomain, path), nil
+}
+
+// serviceURL returns the URL of path on the given subdomain of host.
+func serviceURL(host, subdomain, path string) string {
+	scheme := "https"
+	if i := strings.Index(host, "://"); i != -1 {
+		scheme, host = host[:i], host[i+len("://"):]
+	}
+	host = strings.TrimSuffix(host, "/")
+
+	if rest := strings.TrimPrefix(host, apiSubdomain+"."); rest != host && subdomain != "" {
+		host = subdomain + "." + rest
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, host, path)
+}
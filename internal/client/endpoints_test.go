@@ -0,0 +1,109 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_ServiceURL(t *testing.T) {
+	for _, fixture := range []struct {
+		name          string
+		config        Config
+		env           map[string]string
+		subdomain     string
+		path          string
+		expectedValue string
+		expectedErr   string
+	}{
+		{
+			name:          "should default to the us region",
+			subdomain:     "marketplace",
+			path:          "/v1/marketplace/authenticated/graphql",
+			expectedValue: "https://marketplace.us.lifeomic.com/v1/marketplace/authenticated/graphql",
+		},
+		{
+			name:          "should derive service hosts from the host",
+			config:        Config{Host: "api.eu.lifeomic.com"},
+			subdomain:     "marketplace",
+			path:          "/graphql",
+			expectedValue: "https://marketplace.eu.lifeomic.com/graphql",
+		},
+		{
+			name:          "should derive hosts from the region",
+			config:        Config{Region: "eu"},
+			subdomain:     "api",
+			path:          "/v1",
+			expectedValue: "https://api.eu.lifeomic.com/v1",
+		},
+		{
+			name:          "should prefer the host to the region",
+			config:        Config{Host: "api.eu.lifeomic.com"},
+			env:           map[string]string{RegionEnvVar: "us"},
+			subdomain:     "api",
+			path:          "/v1",
+			expectedValue: "https://api.eu.lifeomic.com/v1",
+		},
+		{
+			name:          "should prefer the configured region to the host environment variable",
+			config:        Config{Region: "eu"},
+			env:           map[string]string{HostEnvVar: "api.us.lifeomic.com"},
+			subdomain:     "api",
+			path:          "/v1",
+			expectedValue: "https://api.eu.lifeomic.com/v1",
+		},
+		{
+			name:          "should use the region environment variable",
+			env:           map[string]string{RegionEnvVar: "eu"},
+			subdomain:     "marketplace",
+			path:          "/graphql",
+			expectedValue: "https://marketplace.eu.lifeomic.com/graphql",
+		},
+		{
+			name:          "should serve every service from hosts without an api label",
+			config:        Config{Host: "http://localhost:8080/"},
+			subdomain:     "marketplace",
+			path:          "/graphql",
+			expectedValue: "http://localhost:8080/graphql",
+		},
+		{
+			name: "should prefer endpoint overrides",
+			config: Config{
+				Host:        "api.eu.lifeomic.com",
+				ServiceName: "marketplace-service",
+				Endpoints:   map[string]string{"marketplace-service": "http://localhost:3000/graphql"},
+			},
+			subdomain:     "marketplace",
+			path:          "/graphql",
+			expectedValue: "http://localhost:3000/graphql",
+		},
+		{
+			name:        "should reject unknown regions",
+			config:      Config{Region: "mars"},
+			expectedErr: `unknown region "mars", expected one of eu, us`,
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			t.Setenv(HostEnvVar, "")
+			t.Setenv(RegionEnvVar, "")
+			for key, value := range fixture.env {
+				t.Setenv(key, value)
+			}
+
+			value, err := fixture.config.ServiceURL(fixture.subdomain, fixture.path)
+			if err != nil {
+				if fixture.expectedErr == "" {
+					t.Errorf("unexpected error: %s", err)
+					return
+				}
+				assert.ErrorContains(t, err, fixture.expectedErr)
+				return
+			} else if fixture.expectedErr != "" {
+				t.Errorf("expected error matching: %s; got nil", fixture.expectedErr)
+				return
+			}
+
+			assert.Equal(t, fixture.expectedValue, value)
+		})
+	}
+}
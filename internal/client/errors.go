@@ -0,0 +1,141 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBodyLength is how much of a response body is included in an
+// APIError's message when the API didn't respond with a JSON error.
+const maxErrorBodyLength = 256
+
+// requestIDHeaders are the response headers which may contain the ID of a
+// request, in order of preference.
+var requestIDHeaders = []string{
+	"X-Request-Id",
+	"X-Amzn-RequestId",
+	"Apigw-Requestid",
+}
+
+// requestID returns the ID of the request a response's header belongs to, or
+// an empty string if the API didn't include one.
+func requestID(header http.Header) string {
+	for _, name := range requestIDHeaders {
+		if id := header.Get(name); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// APIError is returned for requests which the PHC API responded to with an
+// error status. Use errors.As, or the IsNotFound, IsConflict, and
+// IsRateLimited helpers, to inspect it.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Method and Path identify the request that failed.
+	Method string
+	Path   string
+	// RequestID is the ID the API assigned to the request, if any.
+	RequestID string
+	// Message is the error message from the response body. It's empty if
+	// the body isn't a JSON error.
+	Message string
+	// Body is the raw response body.
+	Body []byte
+}
+
+// apiErrorBody is the JSON body of PHC API error responses.
+type apiErrorBody struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+// NewAPIError creates an APIError from an error response and its body,
+// which the caller has already read.
+func NewAPIError(res *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: res.StatusCode,
+		Body:       body,
+	}
+	if res.Request != nil {
+		apiErr.Method = res.Request.Method
+		apiErr.Path = res.Request.URL.Path
+		if apiErr.Path == "" {
+			apiErr.Path = "/"
+		}
+	}
+	apiErr.RequestID = requestID(res.Header)
+
+	var errBody apiErrorBody
+	if err := json.Unmarshal(body, &errBody); err == nil {
+		apiErr.Message = errBody.Error
+		if apiErr.Message == "" {
+			apiErr.Message = errBody.Message
+		}
+	}
+	return apiErr
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	if e.Method != "" {
+		fmt.Fprintf(&b, "%s %s: ", e.Method, e.Path)
+	}
+	fmt.Fprintf(&b, "%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+
+	if message := e.message(); message != "" {
+		fmt.Fprintf(&b, ": %s", message)
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, " (request ID: %s)", e.RequestID)
+	}
+	return b.String()
+}
+
+// message returns the error message, or the start of the response body if
+// there's none.
+func (e *APIError) message() string {
+	if e.Message != "" {
+		return e.Message
+	}
+
+	body := strings.TrimSpace(string(e.Body))
+	if len(body) > maxErrorBodyLength {
+		body = body[:maxErrorBodyLength] + "..."
+	}
+	return body
+}
+
+// IsNotFound reports whether err is an APIError for a resource which doesn't
+// exist.
+func IsNotFound(err error) bool {
+	return hasStatusCode(err, http.StatusNotFound)
+}
+
+// IsConflict reports whether err is an APIError for a request which
+// conflicts with an existing resource.
+func IsConflict(err error) bool {
+	return hasStatusCode(err, http.StatusConflict)
+}
+
+// IsRateLimited reports whether err is an APIError for a request which was
+// rejected because too many requests were sent.
+func IsRateLimited(err error) bool {
+	return hasStatusCode(err, http.StatusTooManyRequests)
+}
+
+// IsForbidden reports whether err is an APIError for a request the caller
+// isn't allowed to make.
+func IsForbidden(err error) bool {
+	return hasStatusCode(err, http.StatusForbidden)
+}
+
+func hasStatusCode(err error, statusCode int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == statusCode
+}
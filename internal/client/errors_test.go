@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckResponse(t *testing.T) {
+	t.Setenv(UseLambdaEnvVar, "")
+
+	for _, fixture := range []struct {
+		name          string
+		statusCode    int
+		contentType   string
+		body          string
+		expectedValue *APIError
+		expectedErr   string
+		expectedIs    string
+	}{
+		{
+			name:        "should decode JSON errors",
+			statusCode:  http.StatusNotFound,
+			contentType: "application/json",
+			body:        `{"error": "Policy not found"}`,
+			expectedValue: &APIError{
+				StatusCode: http.StatusNotFound,
+				Method:     http.MethodGet,
+				Path:       "/policies/my-policy",
+				RequestID:  "my-request",
+				Message:    "Policy not found",
+				Body:       []byte(`{"error": "Policy not found"}`),
+			},
+			expectedErr: "GET /policies/my-policy: 404 Not Found: Policy not found (request ID: my-request)",
+			expectedIs:  "IsNotFound",
+		},
+		{
+			name:        "should use the message field",
+			statusCode:  http.StatusConflict,
+			contentType: "application/json",
+			body:        `{"message": "Policy already exists"}`,
+			expectedErr: "409 Conflict: Policy already exists",
+			expectedIs:  "IsConflict",
+		},
+		{
+			name:        "should return errors with bodies which aren't JSON",
+			statusCode:  http.StatusInternalServerError,
+			contentType: "text/html",
+			body:        "<html>Internal Server Error</html>",
+			expectedErr: "500 Internal Server Error: <html>Internal Server Error</html>",
+		},
+		{
+			name:        "should return errors without bodies",
+			statusCode:  http.StatusTooManyRequests,
+			expectedErr: "GET /policies/my-policy: 429 Too Many Requests (request ID: my-request)",
+			expectedIs:  "IsRateLimited",
+		},
+		{
+			name:        "should return forbidden errors",
+			statusCode:  http.StatusForbidden,
+			contentType: "application/json",
+			body:        `{"error": "Forbidden"}`,
+			expectedErr: "403 Forbidden: Forbidden",
+			expectedIs:  "IsForbidden",
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if fixture.contentType != "" {
+					w.Header().Set("Content-Type", fixture.contentType)
+				}
+				w.Header().Set("X-Request-Id", "my-request")
+				w.WriteHeader(fixture.statusCode)
+				fmt.Fprint(w, fixture.body)
+			}))
+			t.Cleanup(server.Close)
+
+			client, err := New(Config{
+				AuthToken:   "my-token",
+				MaxRetries:  -1,
+				ServiceName: "account-service",
+				Endpoints:   map[string]string{"account-service": server.URL},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = client.Policies().Get(context.Background(), "my-policy")
+			if !assert.Error(t, err) {
+				return
+			}
+			assert.ErrorContains(t, err, fixture.expectedErr)
+
+			// Helpers should also match wrapped errors.
+			wrapped := fmt.Errorf("failed to get policy: %w", err)
+			var apiErr *APIError
+			if assert.True(t, errors.As(wrapped, &apiErr)) && fixture.expectedValue != nil {
+				assert.Equal(t, fixture.expectedValue, apiErr)
+			}
+			for name, is := range map[string]func(error) bool{
+				"IsNotFound":    IsNotFound,
+				"IsConflict":    IsConflict,
+				"IsRateLimited": IsRateLimited,
+				"IsForbidden":   IsForbidden,
+			} {
+				assert.Equal(t, name == fixture.expectedIs, is(wrapped), name)
+			}
+		})
+	}
+}
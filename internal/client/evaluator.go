@@ -0,0 +1,243 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Attributes maps ABAC attributes (e.g. "user.id" or "resource.dataset") to
+// their values for evaluating a PolicyDocument. Values may be strings, string
+// slices, or any other scalar, which is compared by its string
+// representation. Attributes may also be nested, e.g.
+//
+//	attributes := Attributes{"user": map[string]any{"id": "johndoe"}}
+//
+// is equivalent to
+//
+//	attributes := Attributes{"user.id": "johndoe"}
+type Attributes map[string]any
+
+// Decision is the result of evaluating a PolicyDocument for an operation.
+type Decision struct {
+	// Allowed is true if the operation is permitted.
+	Allowed bool
+	// Rule is the RuleMap which permitted the operation. It's nil if the
+	// operation was denied or permitted by a StaticRule.
+	Rule RuleMap
+	// RuleIndex is the index of Rule in the operation's RuleMappings, or -1
+	// if Rule is nil.
+	RuleIndex int
+}
+
+// Evaluate determines whether the given operation is permitted by the policy
+// document for a request with the given user and resource attributes.
+//
+// An operation without a rule is denied. For RuleMappings, the operation is
+// permitted if any RuleMap matches, and a RuleMap matches if all of its
+// comparisons hold. Comparisons against attributes which aren't set never
+// hold, except for ComparisonExists which is how to test for them.
+//
+// An error is returned for unsupported comparison types.
+func (d PolicyDocument) Evaluate(operation string, attributes Attributes) (Decision, error) {
+	decision := Decision{RuleIndex: -1}
+
+	switch rule := d.Rules[operation].(type) {
+	case StaticRule:
+		decision.Allowed = bool(rule)
+
+	case RuleMappings:
+		for i, ruleMap := range rule {
+			matches, err := ruleMap.Evaluate(attributes)
+			if err != nil {
+				return decision, fmt.Errorf("failed to evaluate rule %d for operation %q: %w", i, operation, err)
+			}
+
+			if matches {
+				decision.Allowed = true
+				decision.Rule = ruleMap
+				decision.RuleIndex = i
+				break
+			}
+		}
+	}
+
+	return decision, nil
+}
+
+// Evaluate reports whether every comparison in the RuleMap holds for the
+// given attributes.
+func (r RuleMap) Evaluate(attributes Attributes) (bool, error) {
+	if len(r) == 0 {
+		return false, nil
+	}
+
+	for subject, comparison := range r {
+		matches, err := evaluateComparison(subject, comparison, attributes)
+		if err != nil || !matches {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// evaluateComparison reports whether the comparison holds for the subject
+// attribute. Attributes and comparison values are both treated as lists of
+// strings, where a scalar is a list of one. For example, includes holds if
+// the attribute has any of the values, superset holds if it has all of them,
+// and in holds if all of the attribute's elements are among the values.
+func evaluateComparison(subject string, comparison Comparison, attributes Attributes) (bool, error) {
+	comparisonType := comparison.GetComparisonType()
+
+	attribute, ok := attributes.lookup(subject)
+	if comparisonType == ComparisonExists {
+		return ok, nil
+	}
+	if !ok {
+		return false, nil
+	}
+
+	// Resolve the values to compare the subject attribute with.
+	var values []string
+	switch c := comparison.(type) {
+	case ValueComparison:
+		values = []string{c.Value}
+	case *ValueComparison:
+		values = []string{c.Value}
+	case MultivalueComparison:
+		values = c.Values
+	case *MultivalueComparison:
+		values = c.Values
+	case TargetComparison:
+		if values, ok = attributes.lookup(c.Target); !ok {
+			return false, nil
+		}
+	case *TargetComparison:
+		if values, ok = attributes.lookup(c.Target); !ok {
+			return false, nil
+		}
+	default:
+		return false, fmt.Errorf("comparison %q on %q has no value to compare with", comparisonType, subject)
+	}
+
+	switch comparisonType {
+	case ComparisonEquals:
+		return equalValues(attribute, values), nil
+	case ComparisonNotEquals:
+		return !equalValues(attribute, values), nil
+	case ComparisonIncludes:
+		return intersects(attribute, values), nil
+	case ComparisonNotIncludes:
+		return !intersects(attribute, values), nil
+	case ComparisonIn:
+		return len(attribute) != 0 && isSubset(attribute, values), nil
+	case ComparisonNotIn:
+		return !intersects(attribute, values), nil
+	case ComparisonSuperset:
+		return isSubset(values, attribute), nil
+	case ComparisonSubset:
+		return isSubset(attribute, values), nil
+	case ComparisonStartsWith:
+		return anyPair(attribute, values, strings.HasPrefix), nil
+	case ComparisonPrefixOf:
+		return anyPair(values, attribute, strings.HasPrefix), nil
+	case ComparisonEndsWith:
+		return anyPair(attribute, values, strings.HasSuffix), nil
+	case ComparisonSuffixOf:
+		return anyPair(values, attribute, strings.HasSuffix), nil
+	}
+
+	return false, fmt.Errorf("unsupported comparison type %q", comparisonType)
+}
+
+// lookup gets the values of an attribute. Dotted keys take precedence over
+// nested maps.
+func (a Attributes) lookup(name string) ([]string, bool) {
+	if value, ok := a[name]; ok {
+		return attributeValues(value)
+	}
+
+	var current any = a
+	for _, key := range strings.Split(name, ".") {
+		var ok bool
+		switch m := current.(type) {
+		case Attributes:
+			current, ok = m[key]
+		case map[string]any:
+			current, ok = m[key]
+		case map[string]string:
+			current, ok = m[key]
+		}
+		if !ok {
+			return nil, false
+		}
+	}
+	return attributeValues(current)
+}
+
+// attributeValues converts an attribute value to a slice of strings.
+func attributeValues(value any) ([]string, bool) {
+	switch v := value.(type) {
+	case nil:
+		return nil, false
+	case string:
+		return []string{v}, true
+	case []string:
+		return v, true
+	case []any:
+		values := make([]string, len(v))
+		for i, elem := range v {
+			values[i] = fmt.Sprint(elem)
+		}
+		return values, true
+	case map[string]any, map[string]string, Attributes:
+		return nil, false
+	default:
+		return []string{fmt.Sprint(v)}, true
+	}
+}
+
+// equalValues reports whether a and b have the same elements in the same
+// order.
+func equalValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// intersects reports whether a and b have any element in common.
+func intersects(a, b []string) bool {
+	return anyPair(a, b, func(x, y string) bool { return x == y })
+}
+
+// isSubset reports whether every element of a is in b.
+func isSubset(a, b []string) bool {
+	set := make(map[string]struct{}, len(b))
+	for _, elem := range b {
+		set[elem] = struct{}{}
+	}
+
+	for _, elem := range a {
+		if _, ok := set[elem]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// anyPair reports whether fn holds for any pair of elements from a and b.
+func anyPair(a, b []string, fn func(x, y string) bool) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if fn(x, y) {
+				return true
+			}
+		}
+	}
+	return false
+}
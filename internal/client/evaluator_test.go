@@ -0,0 +1,208 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyDocument_Evaluate(t *testing.T) {
+	document := PolicyDocument{Rules: PolicyRules{
+		"readData": StaticRule(true),
+		"writeData": RuleMappings{
+			{"user.groups": &ValueComparison{Comparison: ComparisonIncludes, Value: "admin"}},
+			{"user.patients": &TargetComparison{Comparison: ComparisonIncludes, Target: "resource.subject"}},
+		},
+		"deleteData": StaticRule(false),
+	}}
+
+	for _, fixture := range []struct {
+		name             string
+		operation        string
+		attributes       Attributes
+		expectedDecision Decision
+	}{
+		{
+			name:             "should allow static rules",
+			operation:        "readData",
+			expectedDecision: Decision{Allowed: true, RuleIndex: -1},
+		},
+		{
+			name:             "should deny disabled static rules",
+			operation:        "deleteData",
+			expectedDecision: Decision{RuleIndex: -1},
+		},
+		{
+			name:             "should deny operations without rules",
+			operation:        "downloadData",
+			expectedDecision: Decision{RuleIndex: -1},
+		},
+		{
+			name:       "should return the first matching rule",
+			operation:  "writeData",
+			attributes: Attributes{"user.groups": []string{"doctor"}, "user.patients": []string{"p1"}, "resource.subject": "p1"},
+			expectedDecision: Decision{
+				Allowed:   true,
+				Rule:      RuleMap{"user.patients": &TargetComparison{Comparison: ComparisonIncludes, Target: "resource.subject"}},
+				RuleIndex: 1,
+			},
+		},
+		{
+			name:             "should deny if no rule matches",
+			operation:        "writeData",
+			attributes:       Attributes{"user.groups": []string{"doctor"}},
+			expectedDecision: Decision{RuleIndex: -1},
+		},
+		{
+			name:             "should support nested attributes",
+			operation:        "writeData",
+			attributes:       Attributes{"user": map[string]any{"groups": []any{"admin"}}},
+			expectedDecision: Decision{Allowed: true, Rule: document.Rules["writeData"].(RuleMappings)[0], RuleIndex: 0},
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			decision, err := document.Evaluate(fixture.operation, fixture.attributes)
+			if err != nil {
+				t.Errorf("unexpected error: %s", err)
+				return
+			}
+			assert.Equal(t, fixture.expectedDecision, decision)
+		})
+	}
+}
+
+func TestRuleMap_Evaluate(t *testing.T) {
+	for _, fixture := range []struct {
+		name          string
+		ruleMap       RuleMap
+		attributes    Attributes
+		expectedValue bool
+		expectedErr   string
+	}{
+		{
+			name:          "equals",
+			ruleMap:       RuleMap{"user.id": ValueComparison{Comparison: ComparisonEquals, Value: "bob"}},
+			attributes:    Attributes{"user.id": "bob"},
+			expectedValue: true,
+		},
+		{
+			name:          "notEquals",
+			ruleMap:       RuleMap{"user.id": ValueComparison{Comparison: ComparisonNotEquals, Value: "bob"}},
+			attributes:    Attributes{"user.id": "bob"},
+			expectedValue: false,
+		},
+		{
+			name:          "notEquals on unset attribute",
+			ruleMap:       RuleMap{"user.id": ValueComparison{Comparison: ComparisonNotEquals, Value: "bob"}},
+			expectedValue: false,
+		},
+		{
+			name:          "includes",
+			ruleMap:       RuleMap{"user.groups": MultivalueComparison{Comparison: ComparisonIncludes, Values: []string{"admin", "doctor"}}},
+			attributes:    Attributes{"user.groups": []string{"doctor"}},
+			expectedValue: true,
+		},
+		{
+			name:          "notIncludes",
+			ruleMap:       RuleMap{"user.groups": ValueComparison{Comparison: ComparisonNotIncludes, Value: "admin"}},
+			attributes:    Attributes{"user.groups": []string{"doctor"}},
+			expectedValue: true,
+		},
+		{
+			name:          "in",
+			ruleMap:       RuleMap{"resource.dataset": MultivalueComparison{Comparison: ComparisonIn, Values: []string{"d1", "d2"}}},
+			attributes:    Attributes{"resource.dataset": "d2"},
+			expectedValue: true,
+		},
+		{
+			name:          "in target",
+			ruleMap:       RuleMap{"resource.subject": TargetComparison{Comparison: ComparisonIn, Target: "user.patients"}},
+			attributes:    Attributes{"resource.subject": "p3", "user.patients": []string{"p1", "p2"}},
+			expectedValue: false,
+		},
+		{
+			name:          "notIn",
+			ruleMap:       RuleMap{"resource.dataset": MultivalueComparison{Comparison: ComparisonNotIn, Values: []string{"d1", "d2"}}},
+			attributes:    Attributes{"resource.dataset": "d3"},
+			expectedValue: true,
+		},
+		{
+			name:          "exists",
+			ruleMap:       RuleMap{"resource.cohorts": ExistsComparison{Comparison: ComparisonExists}},
+			attributes:    Attributes{"resource.cohorts": []string{}},
+			expectedValue: true,
+		},
+		{
+			name:          "exists on unset attribute",
+			ruleMap:       RuleMap{"resource.cohorts": ExistsComparison{Comparison: ComparisonExists}},
+			expectedValue: false,
+		},
+		{
+			name:          "superset",
+			ruleMap:       RuleMap{"user.groups": MultivalueComparison{Comparison: ComparisonSuperset, Values: []string{"admin", "doctor"}}},
+			attributes:    Attributes{"user.groups": []string{"doctor", "nurse", "admin"}},
+			expectedValue: true,
+		},
+		{
+			name:          "subset",
+			ruleMap:       RuleMap{"user.groups": MultivalueComparison{Comparison: ComparisonSubset, Values: []string{"admin", "doctor"}}},
+			attributes:    Attributes{"user.groups": []string{"doctor", "nurse"}},
+			expectedValue: false,
+		},
+		{
+			name:          "startsWith",
+			ruleMap:       RuleMap{"resource.path": ValueComparison{Comparison: ComparisonStartsWith, Value: "/shared/"}},
+			attributes:    Attributes{"resource.path": "/shared/file.txt"},
+			expectedValue: true,
+		},
+		{
+			name:          "prefixOf",
+			ruleMap:       RuleMap{"user.path": TargetComparison{Comparison: ComparisonPrefixOf, Target: "resource.path"}},
+			attributes:    Attributes{"user.path": "/home/bob/", "resource.path": "/home/bob/notes.txt"},
+			expectedValue: true,
+		},
+		{
+			name:          "endsWith",
+			ruleMap:       RuleMap{"resource.name": ValueComparison{Comparison: ComparisonEndsWith, Value: ".vcf"}},
+			attributes:    Attributes{"resource.name": "sample.bam"},
+			expectedValue: false,
+		},
+		{
+			name:          "suffixOf",
+			ruleMap:       RuleMap{"user.domain": ValueComparison{Comparison: ComparisonSuffixOf, Value: "bob@lifeomic.com"}},
+			attributes:    Attributes{"user.domain": "@lifeomic.com"},
+			expectedValue: true,
+		},
+		{
+			name: "should require all comparisons",
+			ruleMap: RuleMap{
+				"user.id":     ValueComparison{Comparison: ComparisonEquals, Value: "bob"},
+				"user.groups": ValueComparison{Comparison: ComparisonIncludes, Value: "admin"},
+			},
+			attributes:    Attributes{"user.id": "bob", "user.groups": []string{"doctor"}},
+			expectedValue: false,
+		},
+		{
+			name:        "should reject unsupported comparisons",
+			ruleMap:     RuleMap{"user.id": ValueComparison{Comparison: "contains", Value: "bob"}},
+			attributes:  Attributes{"user.id": "bob"},
+			expectedErr: `unsupported comparison type "contains"`,
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			value, err := fixture.ruleMap.Evaluate(fixture.attributes)
+			if err != nil {
+				if fixture.expectedErr == "" {
+					t.Errorf("unexpected error: %s", err)
+					return
+				}
+				assert.ErrorContains(t, err, fixture.expectedErr)
+			} else if fixture.expectedErr != "" {
+				t.Errorf("expected error matching: %s; got nil", fixture.expectedErr)
+				return
+			}
+
+			assert.Equal(t, fixture.expectedValue, value)
+		})
+	}
+}
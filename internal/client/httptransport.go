@@ -0,0 +1,112 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+const (
+	CABundleEnvVar          = "LIFEOMIC_CA_BUNDLE"
+	ClientCertificateEnvVar = "LIFEOMIC_CLIENT_CERTIFICATE"
+	ClientKeyEnvVar         = "LIFEOMIC_CLIENT_KEY"
+)
+
+// TransportConfig configures the HTTP transport requests to the PHC API are
+// sent with.
+type TransportConfig struct {
+	// CABundle is the path of a PEM file of CA certificates to trust, in
+	// addition to the system's. It defaults to $LIFEOMIC_CA_BUNDLE.
+	CABundle string
+	// ClientCertificate and ClientKey are the paths of the PEM encoded
+	// certificate and private key to authenticate with using mutual TLS.
+	// They default to $LIFEOMIC_CLIENT_CERTIFICATE and $LIFEOMIC_CLIENT_KEY.
+	ClientCertificate string
+	ClientKey         string
+
+	// Proxy is the URL of the proxy to send requests through. It defaults to
+	// $HTTPS_PROXY, respecting $NO_PROXY.
+	Proxy string
+
+	// ConnectTimeout bounds how long establishing a connection, including the
+	// TLS handshake, may take. Zero uses the defaults of
+	// http.DefaultTransport.
+	ConnectTimeout time.Duration
+}
+
+// NewHTTPTransport creates an http.Transport from config, based on
+// http.DefaultTransport. It's safe to share between clients, so that they
+// share its connections.
+func NewHTTPTransport(config TransportConfig) (*http.Transport, error) {
+	if config.CABundle == "" {
+		config.CABundle = os.Getenv(CABundleEnvVar)
+	}
+	if config.ClientCertificate == "" && config.ClientKey == "" {
+		config.ClientCertificate = os.Getenv(ClientCertificateEnvVar)
+		config.ClientKey = os.Getenv(ClientKeyEnvVar)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if config.CABundle != "" {
+		pool, err := loadCABundle(config.CABundle)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+	switch {
+	case config.ClientCertificate != "" && config.ClientKey != "":
+		certificate, err := tls.LoadX509KeyPair(config.ClientCertificate, config.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	case config.ClientCertificate != "" || config.ClientKey != "":
+		return nil, errors.New("a client certificate requires both a certificate and a key")
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	if config.Proxy != "" {
+		proxy, err := url.Parse(config.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		if proxy.Scheme == "" || proxy.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q: must be an absolute URL such as http://proxy.example.com:3128", config.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if config.ConnectTimeout > 0 {
+		dialer := &net.Dialer{Timeout: config.ConnectTimeout, KeepAlive: 30 * time.Second}
+		transport.DialContext = dialer.DialContext
+		transport.TLSHandshakeTimeout = config.ConnectTimeout
+	}
+	return transport, nil
+}
+
+// loadCABundle returns the system's certificate pool, with the certificates
+// of the PEM file at path added to it.
+func loadCABundle(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in CA bundle %s", path)
+	}
+	return pool, nil
+}
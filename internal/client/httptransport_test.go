@@ -0,0 +1,99 @@
+package client
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHTTPTransport_CABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(server.Close)
+
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caBundle, certificate, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(CABundleEnvVar, "")
+
+	transport, err := NewHTTPTransport(TransportConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = (&http.Client{Transport: transport}).Get(server.URL)
+	assert.ErrorContains(t, err, "certificate", "should reject untrusted certificates")
+
+	transport, err = NewHTTPTransport(TransportConfig{CABundle: caBundle})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if assert.NoError(t, err, "should trust the CA bundle") {
+		res.Body.Close()
+	}
+}
+
+func TestNewHTTPTransport_proxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+	}))
+	t.Cleanup(proxy.Close)
+
+	transport, err := NewHTTPTransport(TransportConfig{Proxy: proxy.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := (&http.Client{Transport: transport}).Get("http://api.example.com/v1/accounts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	assert.Equal(t, "http://api.example.com/v1/accounts", proxied)
+}
+
+func TestNewHTTPTransport_errors(t *testing.T) {
+	emptyBundle := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(emptyBundle, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, fixture := range []struct {
+		name        string
+		config      TransportConfig
+		expectedErr string
+	}{
+		{
+			name:        "should reject CA bundles without certificates",
+			config:      TransportConfig{CABundle: emptyBundle},
+			expectedErr: "no certificates found in CA bundle",
+		},
+		{
+			name:        "should reject missing CA bundles",
+			config:      TransportConfig{CABundle: filepath.Join(t.TempDir(), "missing.pem")},
+			expectedErr: "failed to read CA bundle",
+		},
+		{
+			name:        "should require a client key",
+			config:      TransportConfig{ClientCertificate: "client.pem"},
+			expectedErr: "requires both a certificate and a key",
+		},
+		{
+			name:        "should reject relative proxy URLs",
+			config:      TransportConfig{Proxy: "proxy.example.com"},
+			expectedErr: "invalid proxy URL",
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			_, err := NewHTTPTransport(fixture.config)
+			assert.ErrorContains(t, err, fixture.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// LogSubsystem is the tflog subsystem requests are logged to. Its level
+	// may be set separately with $TF_LOG_PROVIDER_LIFEOMIC_HTTP.
+	LogSubsystem = "http"
+
+	// maxLoggedBodySize is the number of bytes of a body which are logged.
+	// Only as much of a body is buffered for logging.
+	maxLoggedBodySize = 16 * 1024
+
+	// truncatedMarker is appended to logged bodies which were truncated.
+	truncatedMarker = "...[truncated]"
+
+	redacted = "***"
+)
+
+var (
+	// sensitiveHeaders are the headers whose values are never logged. The
+	// policy header carries the provider's sensitive policy_json.
+	sensitiveHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "X-Api-Key", policyHeader}
+
+	// sensitiveFields are the JSON fields whose values are never logged,
+	// lowercased and without separators.
+	sensitiveFields = map[string]bool{
+		"token": true, "accesstoken": true, "refreshtoken": true, "idtoken": true,
+		"clientsecret": true, "secret": true, "password": true, "authorization": true, "apikey": true,
+	}
+
+	// bearerTokenRegexp matches bearer tokens in any logged value, in case
+	// they're echoed by an API.
+	bearerTokenRegexp = regexp.MustCompile(`(?i)bearer\s+[^\s"',]+`)
+
+	// jsonStringFieldRegexp matches the string fields of JSON bodies which
+	// can't be decoded, e.g. because they were truncated. The value's closing
+	// quote is optional as it may have been cut off.
+	jsonStringFieldRegexp = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"(\s*:\s*)"(?:[^"\\]|\\.)*"?`)
+)
+
+type (
+	operationNameKey struct{}
+	logSubsystemKey  struct{}
+)
+
+// WithLogSubsystem returns a context with the http tflog subsystem that
+// LoggingTransport logs to, masking bearer tokens. Contexts used for many
+// requests, e.g. that of a resource operation, should set it up once rather
+// than leaving LoggingTransport to set it up for every request.
+func WithLogSubsystem(ctx context.Context) context.Context {
+	ctx = tflog.NewSubsystem(ctx, LogSubsystem, tflog.WithLevelFromEnv("TF_LOG_PROVIDER_LIFEOMIC", LogSubsystem))
+	ctx = tflog.SubsystemMaskAllFieldValuesRegexes(ctx, LogSubsystem, bearerTokenRegexp)
+	return context.WithValue(ctx, logSubsystemKey{}, true)
+}
+
+// WithOperationName returns a context whose requests are logged with the
+// given operation name, e.g. the name of a GraphQL operation. It's also
+// recorded in the requests' spans.
+func WithOperationName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, operationNameKey{}, name)
+}
+
+// operationName returns the operation name of ctx's requests, if it has one.
+func operationName(ctx context.Context) string {
+	name, _ := ctx.Value(operationNameKey{}).(string)
+	return name
+}
+
+// LoggingTransport logs requests and their responses at the DEBUG level to the
+// provider's http tflog subsystem. Bearer tokens and sensitive headers are
+// masked.
+type LoggingTransport struct {
+	// LogBodies logs request and response bodies at the TRACE level, with
+	// the values of sensitive JSON fields masked.
+	LogBodies bool
+	// SensitiveHeaders are additional headers whose values are masked, e.g.
+	// the custom headers set by Config.Header, which may carry credentials.
+	SensitiveHeaders []string
+
+	Base http.RoundTripper
+}
+
+func (t *LoggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if ctx.Value(logSubsystemKey{}) == nil {
+		ctx = WithLogSubsystem(ctx)
+	}
+
+	fields := map[string]any{
+		"method": req.Method,
+		"url":    req.URL.Redacted(),
+	}
+	if operation := operationName(req.Context()); operation != "" {
+		fields["operation"] = operation
+	}
+
+	tflog.SubsystemDebug(ctx, LogSubsystem, "Sending request", fields)
+	if t.LogBodies {
+		tflog.SubsystemTrace(ctx, LogSubsystem, "Request", withFields(fields, map[string]any{
+			"headers": redactHeaders(req.Header, t.SensitiveHeaders),
+			"body":    requestBody(req),
+		}))
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	start := time.Now()
+	res, err := base.RoundTrip(req)
+	fields["duration_ms"] = time.Since(start).Milliseconds()
+	if err != nil {
+		tflog.SubsystemDebug(ctx, LogSubsystem, "Request failed", withFields(fields, map[string]any{"error": err.Error()}))
+		return nil, err
+	}
+
+	fields["status_code"] = res.StatusCode
+	if id := requestID(res.Header); id != "" {
+		fields["request_id"] = id
+	}
+	tflog.SubsystemDebug(ctx, LogSubsystem, "Received response", fields)
+	if t.LogBodies {
+		// Only the logged part of the body is buffered. It's put back in
+		// front of the rest, which is left for the caller to read.
+		body, truncated, err := readLoggedBody(res.Body)
+		if err != nil {
+			res.Body.Close()
+			return nil, err
+		}
+		res.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), res.Body), Closer: res.Body}
+
+		tflog.SubsystemTrace(ctx, LogSubsystem, "Response", withFields(fields, map[string]any{
+			"headers": redactHeaders(res.Header, t.SensitiveHeaders),
+			"body":    redactBody(body, truncated),
+		}))
+	}
+	return res, nil
+}
+
+// readCloser is an io.ReadCloser reading from Reader and closing Closer.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// readLoggedBody reads the part of r which is logged, reporting whether r
+// was truncated. The bytes it read are returned even if r was truncated.
+func readLoggedBody(r io.Reader) ([]byte, bool, error) {
+	body, err := io.ReadAll(io.LimitReader(r, maxLoggedBodySize+1))
+	if err != nil {
+		return nil, false, err
+	}
+	return body, len(body) > maxLoggedBodySize, nil
+}
+
+// withFields returns a copy of fields with extra fields added.
+func withFields(fields, extra map[string]any) map[string]any {
+	merged := make(map[string]any, len(fields)+len(extra))
+	for k, v := range fields {
+		merged[k] = v
+	}
+	for k, v := range extra {
+		merged[k] = v
+	}
+	return merged
+}
+
+// requestBody returns the redacted body of req, reading it from GetBody so
+// that the body which is sent isn't consumed.
+func requestBody(req *http.Request) string {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody == nil {
+		return ""
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+
+	data, truncated, err := readLoggedBody(body)
+	if err != nil {
+		return ""
+	}
+	return redactBody(data, truncated)
+}
+
+// redactHeaders returns the values of header, masking those of sensitive
+// headers and of the extra headers given. The scheme of the Authorization
+// header is kept.
+func redactHeaders(header http.Header, extra []string) map[string]string {
+	values := make(map[string]string, len(header))
+	for name := range header {
+		values[name] = header.Get(name)
+	}
+	for _, name := range append(append([]string{}, sensitiveHeaders...), extra...) {
+		value := header.Get(name)
+		if value == "" {
+			continue
+		}
+		if scheme, _, ok := strings.Cut(value, " "); ok && name == "Authorization" {
+			values[name] = scheme + " " + redacted
+		} else {
+			values[http.CanonicalHeaderKey(name)] = redacted
+		}
+	}
+	return values
+}
+
+// redactBody returns body for logging, masking the values of sensitive JSON
+// fields and truncating it to maxLoggedBodySize. Bodies which were already
+// truncated are marked as such.
+func redactBody(body []byte, truncated bool) string {
+	var value any
+	if err := json.Unmarshal(body, &value); err == nil {
+		if redactedBody, err := json.Marshal(redactValue(value)); err == nil {
+			body = redactedBody
+		}
+	} else {
+		body = redactJSONFields(body)
+	}
+
+	if truncated || len(body) > maxLoggedBodySize {
+		if len(body) > maxLoggedBodySize {
+			body = body[:maxLoggedBodySize]
+		}
+		return string(body) + truncatedMarker
+	}
+	return string(body)
+}
+
+// redactJSONFields masks the values of sensitive string fields in a body
+// which couldn't be decoded as JSON.
+func redactJSONFields(body []byte) []byte {
+	return jsonStringFieldRegexp.ReplaceAllFunc(body, func(field []byte) []byte {
+		match := jsonStringFieldRegexp.FindSubmatch(field)
+		if !isSensitiveField(string(match[1])) {
+			return field
+		}
+		return []byte(`"` + string(match[1]) + `"` + string(match[2]) + `"` + redacted + `"`)
+	})
+}
+
+// redactValue masks the values of sensitive fields in a decoded JSON value.
+func redactValue(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, field := range value {
+			if isSensitiveField(key) {
+				value[key] = redacted
+			} else {
+				value[key] = redactValue(field)
+			}
+		}
+	case []any:
+		for i, item := range value {
+			value[i] = redactValue(item)
+		}
+	}
+	return value
+}
+
+// isSensitiveField reports whether a JSON field's value must not be logged,
+// e.g. "refresh_token" or "clientSecret".
+func isSensitiveField(key string) bool {
+	key = strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+	return sensitiveFields[key]
+}
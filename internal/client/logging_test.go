@@ -0,0 +1,151 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggingTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "my-request")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"my-access-token","data":{"echo":"Bearer my-token"}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	var output bytes.Buffer
+	ctx := WithOperationName(tflogtest.RootLogger(context.Background(), &output), "GetPolicy")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, strings.NewReader(`{"clientSecret":"my-secret","name":"my-policy"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer my-token")
+
+	transport := &LoggingTransport{LogBodies: true}
+	res, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Contains(t, string(body), "my-access-token", "should leave the response body intact")
+
+	logs := output.String()
+	entries, err := tflogtest.MultilineJSONDecode(&output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, entries, 4) {
+		assert.Equal(t, "Received response", entries[2]["@message"])
+		assert.Equal(t, "GetPolicy", entries[2]["operation"])
+		assert.Equal(t, float64(http.StatusOK), entries[2]["status_code"])
+		assert.Equal(t, "my-request", entries[2]["request_id"])
+		assert.Contains(t, entries[2], "duration_ms")
+	}
+
+	for _, secret := range []string{"my-token", "my-secret", "my-access-token"} {
+		assert.NotContains(t, logs, secret)
+	}
+	assert.Contains(t, logs, "my-policy")
+}
+
+func TestLoggingTransport_largeBody(t *testing.T) {
+	payload := `{"data":"` + strings.Repeat("a", 2*maxLoggedBodySize) + `"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(payload))
+	}))
+	t.Cleanup(server.Close)
+
+	var output bytes.Buffer
+	ctx := WithLogSubsystem(tflogtest.RootLogger(context.Background(), &output))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, strings.NewReader(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transport := &LoggingTransport{LogBodies: true}
+	res, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Equal(t, payload, string(body), "should leave the response body intact")
+
+	entries, err := tflogtest.MultilineJSONDecode(&output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, entries, 4) {
+		for _, entry := range []map[string]any{entries[1], entries[3]} {
+			assert.Equal(t, payload[:maxLoggedBodySize]+truncatedMarker, entry["body"])
+		}
+		assert.NotContains(t, entries[2], "new_logger_warning", "should log to the context's subsystem")
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	for _, fixture := range []struct {
+		name          string
+		body          string
+		truncated     bool
+		expectedValue string
+	}{
+		{
+			name:          "should mask sensitive fields",
+			body:          `{"items":[{"refresh_token":"secret","id":"1"}],"Password":"secret"}`,
+			expectedValue: `{"Password":"***","items":[{"id":"1","refresh_token":"***"}]}`,
+		},
+		{
+			name:          "should leave other bodies alone",
+			body:          "not json",
+			expectedValue: "not json",
+		},
+		{
+			name:          "should truncate long bodies",
+			body:          strings.Repeat("a", maxLoggedBodySize+1),
+			expectedValue: strings.Repeat("a", maxLoggedBodySize) + truncatedMarker,
+		},
+		{
+			name:          "should mark bodies which were truncated when read",
+			body:          "aaa",
+			truncated:     true,
+			expectedValue: "aaa" + truncatedMarker,
+		},
+		{
+			name:          "should mask sensitive fields of truncated JSON",
+			body:          `{"name":"my-policy","access_token": "secret","items":[{"password":"sec`,
+			truncated:     true,
+			expectedValue: `{"name":"my-policy","access_token": "***","items":[{"password":"***"` + truncatedMarker,
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			assert.Equal(t, fixture.expectedValue, redactBody([]byte(fixture.body), fixture.truncated))
+		})
+	}
+}
+
+func TestRedactHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer my-token")
+	header.Set("Cookie", "session=my-session")
+	header.Set("LifeOmic-Policy", `{"rules":{"readData":true}}`)
+	header.Set("X-Custom-Credential", "my-credential")
+	header.Set("Accept", "application/json")
+
+	assert.Equal(t, map[string]string{
+		"Authorization":       "Bearer ***",
+		"Cookie":              "***",
+		"Lifeomic-Policy":     "***",
+		"X-Custom-Credential": "***",
+		"Accept":              "application/json",
+	}, redactHeaders(header, []string{"x-custom-credential"}))
+}
@@ -3,6 +3,7 @@ package client
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/url"
 
 	"github.com/dyninc/qstring"
@@ -11,6 +12,13 @@ import (
 // pagination errors
 var (
 	ErrNoNextPage = errors.New("no next page to fetch")
+	// ErrRepeatedPageToken is returned by an Iterator when the API returns a
+	// next page token it already returned, which would otherwise repeat
+	// pages forever.
+	ErrRepeatedPageToken = errors.New("the API returned a next page token it already returned")
+	// ErrMaxItemsExceeded is returned by an Iterator when there are more
+	// items than the limit set by WithMaxItems.
+	ErrMaxItemsExceeded = errors.New("the list has more items than the limit")
 )
 
 // PaginatedList represents a list of a resource T which can be paginated.
@@ -56,6 +64,153 @@ func (r *ListResponse) GetNextPageToken() string {
 	return url.Query().Get("nextPageToken")
 }
 
+// ListFunc lists a page of a resource T, such as PolicyService.List.
+type ListFunc[T any] func(context.Context, ListOptions) (PaginatedList[T], error)
+
+// ListOption configures how an Iterator lists a resource.
+type ListOption func(*listConfig)
+
+type listConfig struct {
+	options  ListOptions
+	maxItems int
+}
+
+// WithPageSize sets the number of items requested per page. The API's
+// default is used if it isn't set.
+func WithPageSize(size int) ListOption {
+	return func(c *listConfig) {
+		c.options.PageSize = size
+	}
+}
+
+// WithMaxItems limits the number of items listed. Iterating past the limit
+// fails with ErrMaxItemsExceeded.
+func WithMaxItems(max int) ListOption {
+	return func(c *listConfig) {
+		c.maxItems = max
+	}
+}
+
+// Iterator iterates over the items of every page of a resource T, fetching
+// pages as they're needed. Call Next to advance to each item, then check Err
+// once it returns false:
+//
+//	it := client.Iterate(ctx, policies.List)
+//	for it.Next() {
+//		policy := it.Item()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type Iterator[T any] struct {
+	ctx    context.Context
+	list   ListFunc[T]
+	config listConfig
+
+	page    PaginatedList[T]
+	index   int
+	count   int
+	item    T
+	err     error
+	done    bool
+	tokens  map[string]bool
+	started bool
+}
+
+// Iterate returns an Iterator over the items of the resource listed by list.
+// Iteration stops with the context's error if ctx is done.
+func Iterate[T any](ctx context.Context, list ListFunc[T], opts ...ListOption) *Iterator[T] {
+	it := &Iterator[T]{ctx: ctx, list: list, tokens: map[string]bool{}}
+	for _, opt := range opts {
+		opt(&it.config)
+	}
+	return it
+}
+
+// Next advances to the next item, returning false when there are no more
+// items or an error occurred.
+func (it *Iterator[T]) Next() bool {
+	if it.err != nil || it.done {
+		return false
+	}
+	if err := it.ctx.Err(); err != nil {
+		it.err = err
+		return false
+	}
+
+	for it.page == nil || it.index >= len(it.page.Items()) {
+		if !it.fetchPage() {
+			return false
+		}
+	}
+
+	if it.config.maxItems > 0 && it.count >= it.config.maxItems {
+		it.err = fmt.Errorf("%w of %d", ErrMaxItemsExceeded, it.config.maxItems)
+		return false
+	}
+
+	it.item = it.page.Items()[it.index]
+	it.index++
+	it.count++
+	return true
+}
+
+// fetchPage fetches the first or next page, returning false if there isn't
+// one or it failed.
+func (it *Iterator[T]) fetchPage() bool {
+	options := it.config.options
+	if it.started {
+		if !it.page.HasNextPage() {
+			it.done = true
+			return false
+		}
+
+		token := it.page.GetNextPageToken()
+		switch {
+		case token == "":
+			it.err = errors.New("the next page link has no nextPageToken")
+			return false
+		case it.tokens[token]:
+			it.err = ErrRepeatedPageToken
+			return false
+		}
+		it.tokens[token] = true
+		options.NextPageToken = token
+	}
+	it.started = true
+
+	page, err := it.list(it.ctx, options)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.page = page
+	it.index = 0
+	return true
+}
+
+// Item returns the current item.
+func (it *Iterator[T]) Item() T {
+	return it.item
+}
+
+// Err returns the error which stopped iteration, if any.
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// CollectAll returns the items of every page of the resource listed by list.
+// Use WithMaxItems to bound how many items are collected; the items up to
+// the limit are returned along with ErrMaxItemsExceeded if there are more.
+func CollectAll[T any](ctx context.Context, list ListFunc[T], opts ...ListOption) ([]T, error) {
+	var items []T
+	it := Iterate(ctx, list, opts...)
+	for it.Next() {
+		items = append(items, it.Item())
+	}
+	return items, it.Err()
+}
+
 // buildQueryURL formats an endpoint with query parameters.
 func buildQueryURL[T any](endpoint string, params *T) (string, error) {
 	query, err := qstring.MarshalString(params)
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newPolicyListServer serves pages of policies named by pages, keyed by their
+// nextPageToken. The first page has no token.
+func newPolicyListServer(t *testing.T, pages map[string][]string, nextTokens map[string]string) (PolicyService, *[]string) {
+	t.Setenv(UseLambdaEnvVar, "")
+
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		token := r.URL.Query().Get("nextPageToken")
+
+		var items string
+		for i, name := range pages[token] {
+			if i > 0 {
+				items += ","
+			}
+			items += fmt.Sprintf(`{"name": %q, "policy": {"rules": {}}}`, name)
+		}
+		next := "null"
+		if nextToken, ok := nextTokens[token]; ok {
+			next = fmt.Sprintf(`"http://%s/policies?nextPageToken=%s"`, r.Host, nextToken)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"items": [%s], "links": {"self": "", "next": %s}}`, items, next)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := New(Config{
+		AccountID:   "my-account",
+		ServiceName: "account-service",
+		Endpoints:   map[string]string{"account-service": server.URL},
+		MaxRetries:  -1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client.Policies(), &queries
+}
+
+func policyNames(policies []Policy) []string {
+	names := make([]string, len(policies))
+	for i, policy := range policies {
+		names[i] = policy.Name
+	}
+	return names
+}
+
+func TestCollectAll(t *testing.T) {
+	pages := map[string][]string{"": {"a", "b"}, "page2": {}, "page3": {"c"}}
+
+	for _, fixture := range []struct {
+		name          string
+		nextTokens    map[string]string
+		options       []ListOption
+		expectedValue []string
+		expectedErr   error
+	}{
+		{
+			name:          "should collect every page",
+			nextTokens:    map[string]string{"": "page2", "page2": "page3"},
+			expectedValue: []string{"a", "b", "c"},
+		},
+		{
+			name:          "should stop at the max items",
+			nextTokens:    map[string]string{"": "page2", "page2": "page3"},
+			options:       []ListOption{WithMaxItems(2)},
+			expectedValue: []string{"a", "b"},
+			expectedErr:   ErrMaxItemsExceeded,
+		},
+		{
+			name:          "should allow exactly the max items",
+			nextTokens:    map[string]string{"": "page2", "page2": "page3"},
+			options:       []ListOption{WithMaxItems(3)},
+			expectedValue: []string{"a", "b", "c"},
+		},
+		{
+			name:          "should stop when a page token repeats",
+			nextTokens:    map[string]string{"": "page2", "page2": "page2"},
+			expectedValue: []string{"a", "b"},
+			expectedErr:   ErrRepeatedPageToken,
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			policies, _ := newPolicyListServer(t, pages, fixture.nextTokens)
+
+			items, err := CollectAll(context.Background(), policies.List, fixture.options...)
+			if fixture.expectedErr != nil {
+				assert.ErrorIs(t, err, fixture.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, fixture.expectedValue, policyNames(items))
+		})
+	}
+}
+
+func TestIterate_pageSize(t *testing.T) {
+	policies, queries := newPolicyListServer(t,
+		map[string][]string{"": {"a"}, "page2": {"b"}},
+		map[string]string{"": "page2"})
+
+	items, err := CollectAll(context.Background(), policies.List, WithPageSize(1))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, policyNames(items))
+	assert.Equal(t, []string{"pageSize=1", "nextPageToken=page2&pageSize=1"}, *queries)
+}
+
+func TestIterate_context(t *testing.T) {
+	policies, queries := newPolicyListServer(t, map[string][]string{"": {"a"}}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	it := Iterate(ctx, policies.List)
+	assert.False(t, it.Next())
+	assert.ErrorIs(t, it.Err(), context.Canceled)
+	assert.Empty(t, *queries, "should not list pages after the context is done")
+}
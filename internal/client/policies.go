@@ -12,7 +12,8 @@ import (
 // the PHC API.
 // See: https://api.docs.lifeomic.com/#tag/Policy
 type PolicyService interface {
-	// List returns all policies.
+	// List returns a page of policies. Use Iterate or CollectAll to list
+	// all of them.
 	// See: https://api.docs.lifeomic.com/#tag/Policy/operation/list-policies
 	List(context.Context, ListOptions) (PaginatedList[Policy], error)
 	// Create creates a new policy.
@@ -181,6 +182,9 @@ func (r *RuleMap) UnmarshalJSON(b []byte) error {
 	case mapHasKey(comparisonMap, "target"):
 		comparison = &TargetComparison{}
 
+	case comparisonMap["comparison"] == string(ComparisonExists):
+		comparison = &ExistsComparison{}
+
 	default:
 		return errors.New("malformed comparison object")
 	}
@@ -196,7 +200,8 @@ func (r *RuleMap) UnmarshalJSON(b []byte) error {
 // Comparison represents a generic ABAC comparison. It's a wrapper around the
 // polymorphic JSON which can either express a comparison between an attribute
 // and another attribute (TargetComparison), a single value (ValueComparison),
-// or an array of values (MultivalueComparison).
+// an array of values (MultivalueComparison), or no value at all
+// (ExistsComparison).
 // See: https://devcenter.docs.lifeomic.com/development/abac-syntax#comparisons
 type Comparison interface {
 	GetComparisonType() ComparisonType
@@ -230,6 +235,14 @@ type TargetComparison struct {
 
 func (c TargetComparison) GetComparisonType() ComparisonType { return c.Comparison }
 
+// ExistsComparison represents an ABAC comparison which only checks that an
+// attribute is set.
+type ExistsComparison struct {
+	Comparison ComparisonType `json:"comparison"`
+}
+
+func (c ExistsComparison) GetComparisonType() ComparisonType { return c.Comparison }
+
 // A ComparisonType represents an ABAC comparison type.
 // See: https://devcenter.docs.lifeomic.com/development/abac-syntax#supported-comparisons
 type ComparisonType string
@@ -251,6 +264,58 @@ const (
 	ComparisonSuffixOf    ComparisonType = "suffixOf"
 )
 
+// ComparisonTypes lists the supported comparison types.
+var ComparisonTypes = []ComparisonType{
+	ComparisonEquals,
+	ComparisonNotEquals,
+	ComparisonIncludes,
+	ComparisonNotIncludes,
+	ComparisonIn,
+	ComparisonNotIn,
+	ComparisonExists,
+	ComparisonSuperset,
+	ComparisonSubset,
+	ComparisonStartsWith,
+	ComparisonPrefixOf,
+	ComparisonEndsWith,
+	ComparisonSuffixOf,
+}
+
+// IsSupported reports whether c is a supported comparison type.
+func (c ComparisonType) IsSupported() bool {
+	for _, comparison := range ComparisonTypes {
+		if c == comparison {
+			return true
+		}
+	}
+	return false
+}
+
+// IsMultivalued reports whether c compares an attribute with a set of values,
+// in which case a MultivalueComparison or TargetComparison should be used.
+func (c ComparisonType) IsMultivalued() bool {
+	switch c {
+	case ComparisonIn, ComparisonNotIn, ComparisonSuperset, ComparisonSubset:
+		return true
+	}
+	return false
+}
+
+// Operations lists the well-known ABAC operations.
+// See: https://devcenter.docs.lifeomic.com/development/abac-syntax#operations
+var Operations = []string{
+	"accessAdmin",
+	"billingAdmin",
+	"deleteData",
+	"downloadData",
+	"inviteProjectMembers",
+	"lifeomicMarketplaceAdmin",
+	"publishContent",
+	"readData",
+	"readMaskedData",
+	"writeData",
+}
+
 type policyService struct {
 	*Client
 }
@@ -29,6 +29,16 @@ func TestRuleMap_UnmarshalJSON(t *testing.T) {
 			json:          `{"user.id": {"comparison": "notEquals", "value": "bob"}}`,
 			expectedValue: RuleMap{"user.id": &ValueComparison{Comparison: ComparisonNotEquals, Value: "bob"}},
 		},
+		{
+			name:          "should parse exists comparison",
+			json:          `{"resource.cohorts": {"comparison": "exists"}}`,
+			expectedValue: RuleMap{"resource.cohorts": &ExistsComparison{Comparison: ComparisonExists}},
+		},
+		{
+			name:        "should reject comparison without value",
+			json:        `{"user.id": {"comparison": "equals"}}`,
+			expectedErr: "malformed comparison object",
+		},
 	} {
 		t.Run(fixture.name, func(t *testing.T) {
 			var value RuleMap
@@ -0,0 +1,126 @@
+package client
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// RateLimit configures a Limiter.
+type RateLimit struct {
+	// RequestsPerSecond is the rate at which requests may be sent. Zero
+	// disables the rate limit.
+	RequestsPerSecond float64
+	// Burst is the number of requests which may be sent at once before
+	// they're limited to RequestsPerSecond. It defaults to 1.
+	Burst int
+	// MaxInFlight is the number of requests which may be awaiting a response
+	// at once. Zero disables the limit.
+	MaxInFlight int
+}
+
+// Limiter limits the requests sent to a service with a token bucket and a
+// maximum number of requests in flight. It's safe for concurrent use, and
+// meant to be shared by all of the service's clients.
+type Limiter struct {
+	limit RateLimit
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+
+	inFlight chan struct{}
+}
+
+// NewLimiter creates a Limiter enforcing limit.
+func NewLimiter(limit RateLimit) *Limiter {
+	if limit.Burst <= 0 {
+		limit.Burst = 1
+	}
+	l := &Limiter{limit: limit, tokens: float64(limit.Burst)}
+	if limit.MaxInFlight > 0 {
+		l.inFlight = make(chan struct{}, limit.MaxInFlight)
+	}
+	return l
+}
+
+// Acquire waits until a request may be sent, returning a func which must be
+// called once the request is done. It returns the context's error if the
+// context is done first. A nil Limiter doesn't limit requests.
+func (l *Limiter) Acquire(ctx context.Context) (release func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	release = func() {}
+	if l.inFlight != nil {
+		select {
+		case l.inFlight <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		release = func() { <-l.inFlight }
+	}
+
+	if err := sleep(ctx, l.reserve()); err != nil {
+		l.cancel()
+		release()
+		return nil, err
+	}
+	return release, nil
+}
+
+// reserve takes a token from the bucket, returning how long to wait until
+// it's available.
+func (l *Limiter) reserve() time.Duration {
+	if l.limit.RequestsPerSecond <= 0 {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.limit.RequestsPerSecond
+		if burst := float64(l.limit.Burst); l.tokens > burst {
+			l.tokens = burst
+		}
+	}
+	l.last = now
+
+	// Tokens go negative while requests are waiting, so that each waits
+	// behind the requests which reserved a token before it.
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.limit.RequestsPerSecond * float64(time.Second))
+}
+
+// cancel returns a token reserved by a request which gave up waiting.
+func (l *Limiter) cancel() {
+	if l.limit.RequestsPerSecond <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens++
+}
+
+// releaseBody releases a request's Limiter, and cancels its timeout, once its
+// response body is closed, since the request is in flight until the body has
+// been read.
+type releaseBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
@@ -0,0 +1,94 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter_rate(t *testing.T) {
+	limiter := NewLimiter(RateLimit{RequestsPerSecond: 100, Burst: 2})
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		release, err := limiter.Acquire(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+	}
+	// The burst is sent at once, and the other two wait 10ms each.
+	assert.GreaterOrEqual(t, time.Since(start), 15*time.Millisecond)
+}
+
+func TestLimiter_context(t *testing.T) {
+	limiter := NewLimiter(RateLimit{RequestsPerSecond: 1, MaxInFlight: 1})
+
+	release, err := limiter.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = limiter.Acquire(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "should stop waiting for a slot when the context is done")
+
+	release()
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = limiter.Acquire(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "should stop waiting for a token when the context is done")
+}
+
+func TestLimiter_nil(t *testing.T) {
+	var limiter *Limiter
+	release, err := limiter.Acquire(context.Background())
+	assert.NoError(t, err)
+	release()
+}
+
+func TestAuthedTransport_Limiter(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}))
+	t.Cleanup(server.Close)
+
+	transport := &AuthedTransport{Limiter: NewLimiter(RateLimit{MaxInFlight: 2})}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			res, err := transport.RoundTrip(req)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			res.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, maxInFlight, int32(2))
+}
@@ -0,0 +1,253 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/lambda"
+)
+
+const (
+	defaultMaxRetries       = 3
+	defaultRetryWaitTime    = 100 * time.Millisecond
+	defaultRetryMaxWaitTime = 5 * time.Second
+)
+
+// RetryPolicy configures how failed requests are retried. Requests are
+// retried with exponential backoff and jitter, or after the delay the API
+// asks for with a Retry-After header.
+type RetryPolicy struct {
+	// MaxRetries is the number of times a request is retried. Zero disables
+	// retries.
+	MaxRetries int
+	// WaitTime is how long to wait before the first retry. The wait time
+	// doubles with every retry.
+	WaitTime time.Duration
+	// MaxWaitTime caps the time waited between attempts, including delays
+	// requested by Retry-After headers.
+	MaxWaitTime time.Duration
+}
+
+// RetryPolicy returns the policy for retrying the config's requests, using
+// defaults for unset values. A negative MaxRetries disables retries.
+func (c Config) RetryPolicy() RetryPolicy {
+	policy := RetryPolicy{
+		MaxRetries:  c.MaxRetries,
+		WaitTime:    c.RetryWaitTime,
+		MaxWaitTime: c.MaxRetryWaitTime,
+	}
+	if policy.MaxRetries == 0 {
+		policy.MaxRetries = defaultMaxRetries
+	} else if policy.MaxRetries < 0 {
+		policy.MaxRetries = 0
+	}
+	if policy.WaitTime <= 0 {
+		policy.WaitTime = defaultRetryWaitTime
+	}
+	if policy.MaxWaitTime <= 0 {
+		policy.MaxWaitTime = defaultRetryMaxWaitTime
+	}
+	if policy.MaxWaitTime < policy.WaitTime {
+		policy.MaxWaitTime = policy.WaitTime
+	}
+	return policy
+}
+
+// Backoff returns how long to wait before the given retry, counting from
+// zero. It grows exponentially from WaitTime up to MaxWaitTime, randomized
+// so that concurrent clients don't retry in lockstep.
+func (p RetryPolicy) Backoff(retry int) time.Duration {
+	backoff := float64(p.WaitTime) * math.Pow(2, float64(retry))
+	if backoff > float64(p.MaxWaitTime) {
+		backoff = float64(p.MaxWaitTime)
+	}
+
+	// Wait at least half of the backoff, plus a random amount up to the
+	// other half.
+	half := time.Duration(backoff / 2)
+	return half + jitter(half)
+}
+
+// wait returns how long to wait before retrying res, honoring its Retry-After
+// header.
+func (p RetryPolicy) wait(retry int, res *http.Response) time.Duration {
+	if res != nil {
+		if after, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok {
+			if after > p.MaxWaitTime {
+				return p.MaxWaitTime
+			}
+			return after
+		}
+	}
+	return p.Backoff(retry)
+}
+
+// parseRetryAfter parses a Retry-After header, which is either a number of
+// seconds or an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		after := time.Until(date)
+		if after < 0 {
+			after = 0
+		}
+		return after, true
+	}
+	return 0, false
+}
+
+var (
+	jitterMu   sync.Mutex
+	jitterRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// jitter returns a random duration in [0, d).
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	jitterMu.Lock()
+	defer jitterMu.Unlock()
+	return time.Duration(jitterRand.Int63n(int64(d)))
+}
+
+// sleep waits for d, returning early with the context's error if it's done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Retry calls fn until it succeeds, returns an error for which retryable is
+// false, or the policy's retries are exhausted, returning the last result.
+// It's for operations which are safe to repeat, such as polling for a
+// resource to become available. A nil retryable retries all errors.
+func Retry[T any](ctx context.Context, policy RetryPolicy, retryable func(error) bool, fn func(context.Context) (T, error)) (T, error) {
+	for retry := 0; ; retry++ {
+		res, err := fn(ctx)
+		if err == nil || retry >= policy.MaxRetries || (retryable != nil && !retryable(err)) {
+			return res, err
+		}
+		if ctxErr := sleep(ctx, policy.Backoff(retry)); ctxErr != nil {
+			return res, err
+		}
+	}
+}
+
+type idempotentKey struct{}
+
+// WithIdempotent returns a context marking its requests as idempotent, so
+// that they're retried even though their HTTP method isn't, e.g. GraphQL
+// queries sent with POST.
+func WithIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+// isIdempotent reports whether req can safely be sent more than once.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	idempotent, _ := req.Context().Value(idempotentKey{}).(bool)
+	return idempotent
+}
+
+// shouldRetry reports whether req, which failed with the given response or
+// error, should be retried.
+func shouldRetry(req *http.Request, res *http.Response, err error) bool {
+	if err != nil {
+		// Requests aren't retried after they were cancelled or their context
+		// timed out, but attempts which exceeded AuthedTransport.Timeout are.
+		return req.Context().Err() == nil && isTransientError(err)
+	}
+
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// isTransientError reports whether err is a network error or timeout which
+// may not happen again. Errors which would only repeat themselves, such as
+// invalid credentials, untrusted certificates and lambda function errors,
+// aren't transient.
+func isTransientError(err error) bool {
+	var (
+		tokenErr           *tokenError
+		functionErr        *lambda.FunctionError
+		unknownAuthority   x509.UnknownAuthorityError
+		certificateInvalid x509.CertificateInvalidError
+		hostname           x509.HostnameError
+		recordHeader       tls.RecordHeaderError
+	)
+	switch {
+	case errors.As(err, &tokenErr), errors.As(err, &functionErr),
+		errors.As(err, &unknownAuthority), errors.As(err, &certificateInvalid),
+		errors.As(err, &hostname), errors.As(err, &recordHeader):
+		return false
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// retryRoundTrip sends req with roundTrip, retrying idempotent requests which
+// failed with a network error, a 429, or a 5xx response.
+func (p RetryPolicy) retryRoundTrip(req *http.Request, roundTrip func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if p.MaxRetries <= 0 || !isIdempotent(req) {
+		return roundTrip(req)
+	}
+
+	for retry := 0; ; retry++ {
+		attempt, ok := rewindRequest(req)
+		if !ok {
+			return roundTrip(req)
+		}
+
+		res, err := roundTrip(attempt)
+		if retry >= p.MaxRetries || !shouldRetry(req, res, err) {
+			return res, err
+		}
+
+		wait := p.wait(retry, res)
+		if res != nil {
+			_, _ = io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+		if ctxErr := sleep(req.Context(), wait); ctxErr != nil {
+			return nil, ctxErr
+		}
+	}
+}
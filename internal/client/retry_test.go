@@ -0,0 +1,339 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/lambda"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_RetryPolicy(t *testing.T) {
+	for _, fixture := range []struct {
+		name          string
+		config        Config
+		expectedValue RetryPolicy
+	}{
+		{
+			name:          "should use defaults",
+			expectedValue: RetryPolicy{MaxRetries: 3, WaitTime: 100 * time.Millisecond, MaxWaitTime: 5 * time.Second},
+		},
+		{
+			name:          "should disable retries",
+			config:        Config{MaxRetries: -1},
+			expectedValue: RetryPolicy{MaxRetries: 0, WaitTime: 100 * time.Millisecond, MaxWaitTime: 5 * time.Second},
+		},
+		{
+			name:          "should keep the max wait time above the wait time",
+			config:        Config{MaxRetries: 5, RetryWaitTime: 10 * time.Second},
+			expectedValue: RetryPolicy{MaxRetries: 5, WaitTime: 10 * time.Second, MaxWaitTime: 10 * time.Second},
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			assert.Equal(t, fixture.expectedValue, fixture.config.RetryPolicy())
+		})
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 10, WaitTime: 100 * time.Millisecond, MaxWaitTime: time.Second}
+	for retry, expected := range []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	} {
+		backoff := policy.Backoff(retry)
+		assert.GreaterOrEqual(t, backoff, expected/2)
+		assert.Less(t, backoff, expected)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	after, ok := parseRetryAfter("2")
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Second, after)
+
+	after, ok = parseRetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.InDelta(t, time.Minute, after, float64(2*time.Second))
+
+	for _, value := range []string{"", "soon", "-1"} {
+		_, ok := parseRetryAfter(value)
+		assert.False(t, ok, value)
+	}
+}
+
+func TestAuthedTransport_Retry(t *testing.T) {
+	for _, fixture := range []struct {
+		name             string
+		method           string
+		idempotent       bool
+		statusCodes      []int
+		retryAfter       string
+		expectedAttempts int
+		expectedStatus   int
+	}{
+		{
+			name:             "should retry idempotent requests",
+			method:           http.MethodGet,
+			statusCodes:      []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+			expectedAttempts: 3,
+			expectedStatus:   http.StatusOK,
+		},
+		{
+			name:             "should give up after the max retries",
+			method:           http.MethodPut,
+			statusCodes:      []int{500, 500, 500, 500, 500},
+			expectedAttempts: 3,
+			expectedStatus:   http.StatusInternalServerError,
+		},
+		{
+			name:             "should honor Retry-After",
+			method:           http.MethodDelete,
+			statusCodes:      []int{http.StatusTooManyRequests, http.StatusNoContent},
+			retryAfter:       "0",
+			expectedAttempts: 2,
+			expectedStatus:   http.StatusNoContent,
+		},
+		{
+			name:             "should not retry client errors",
+			method:           http.MethodGet,
+			statusCodes:      []int{http.StatusBadRequest, http.StatusOK},
+			expectedAttempts: 1,
+			expectedStatus:   http.StatusBadRequest,
+		},
+		{
+			name:             "should not retry non-idempotent requests",
+			method:           http.MethodPost,
+			statusCodes:      []int{http.StatusServiceUnavailable, http.StatusOK},
+			expectedAttempts: 1,
+			expectedStatus:   http.StatusServiceUnavailable,
+		},
+		{
+			name:             "should retry requests marked idempotent",
+			method:           http.MethodPost,
+			idempotent:       true,
+			statusCodes:      []int{http.StatusServiceUnavailable, http.StatusOK},
+			expectedAttempts: 2,
+			expectedStatus:   http.StatusOK,
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			var attempts int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				assert.Equal(t, "{}", string(body), "should resend the body")
+
+				if fixture.retryAfter != "" {
+					w.Header().Set("Retry-After", fixture.retryAfter)
+				}
+				w.WriteHeader(fixture.statusCodes[attempts])
+				attempts++
+			}))
+			t.Cleanup(server.Close)
+
+			ctx := context.Background()
+			if fixture.idempotent {
+				ctx = WithIdempotent(ctx)
+			}
+			req, err := http.NewRequestWithContext(ctx, fixture.method, server.URL, strings.NewReader("{}"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			transport := &AuthedTransport{
+				Retry: RetryPolicy{MaxRetries: 2, WaitTime: time.Millisecond, MaxWaitTime: 10 * time.Millisecond},
+			}
+			res, err := transport.RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+
+			assert.Equal(t, fixture.expectedAttempts, attempts)
+			assert.Equal(t, fixture.expectedStatus, res.StatusCode)
+		})
+	}
+}
+
+func TestAuthedTransport_Retry_context(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transport := &AuthedTransport{Retry: RetryPolicy{MaxRetries: 10, WaitTime: time.Hour, MaxWaitTime: time.Hour}}
+	start := time.Now()
+	_, err = transport.RoundTrip(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second, "should stop waiting when the context is done")
+}
+
+func TestRetry(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 3, WaitTime: time.Millisecond, MaxWaitTime: time.Millisecond}
+	errNotReady := errors.New("not ready")
+	errFatal := errors.New("fatal")
+
+	var calls int
+	value, err := Retry(context.Background(), policy, nil, func(context.Context) (int, error) {
+		calls++
+		if calls < 3 {
+			return 0, errNotReady
+		}
+		return calls, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, value)
+
+	calls = 0
+	_, err = Retry(context.Background(), policy, func(err error) bool { return err != errFatal }, func(context.Context) (int, error) {
+		calls++
+		return 0, errFatal
+	})
+	assert.ErrorIs(t, err, errFatal)
+	assert.Equal(t, 1, calls, "should stop at errors which aren't retryable")
+
+	calls = 0
+	_, err = Retry(context.Background(), policy, nil, func(context.Context) (int, error) {
+		calls++
+		return 0, errNotReady
+	})
+	assert.ErrorIs(t, err, errNotReady)
+	assert.Equal(t, 4, calls, "should give up after the max retries")
+}
+
+// tokenSourceFunc adapts a func to a TokenSource.
+type tokenSourceFunc func(context.Context) (*Token, error)
+
+func (f tokenSourceFunc) Token(ctx context.Context) (*Token, error) {
+	return f(ctx)
+}
+
+func TestShouldRetry_errors(t *testing.T) {
+	connectionRefused := &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+
+	for _, fixture := range []struct {
+		name          string
+		err           error
+		cancelled     bool
+		expectedValue bool
+	}{
+		{
+			name:          "should retry network errors",
+			err:           connectionRefused,
+			expectedValue: true,
+		},
+		{
+			name:          "should retry connection resets",
+			err:           fmt.Errorf("read: %w", syscall.ECONNRESET),
+			expectedValue: true,
+		},
+		{
+			name:          "should retry unexpected EOFs",
+			err:           fmt.Errorf("failed to read response: %w", io.ErrUnexpectedEOF),
+			expectedValue: true,
+		},
+		{
+			name:          "should retry attempts which timed out",
+			err:           context.DeadlineExceeded,
+			expectedValue: true,
+		},
+		{
+			name:          "should not retry cancelled requests",
+			err:           connectionRefused,
+			cancelled:     true,
+			expectedValue: false,
+		},
+		{
+			name:          "should not retry access token errors",
+			err:           &tokenError{err: connectionRefused},
+			expectedValue: false,
+		},
+		{
+			name:          "should not retry untrusted certificates",
+			err:           &url.Error{Op: "Get", URL: "https://example.com", Err: x509.UnknownAuthorityError{}},
+			expectedValue: false,
+		},
+		{
+			name:          "should not retry invalid certificates",
+			err:           x509.CertificateInvalidError{Reason: x509.Expired},
+			expectedValue: false,
+		},
+		{
+			name:          "should not retry hostname mismatches",
+			err:           x509.HostnameError{Certificate: &x509.Certificate{}, Host: "example.com"},
+			expectedValue: false,
+		},
+		{
+			name:          "should not retry TLS handshake errors",
+			err:           tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"},
+			expectedValue: false,
+		},
+		{
+			name:          "should not retry lambda function errors",
+			err:           &lambda.FunctionError{Function: "account-service", Kind: "Unhandled"},
+			expectedValue: false,
+		},
+		{
+			name:          "should not retry other errors",
+			err:           errors.New("unsupported protocol scheme"),
+			expectedValue: false,
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if fixture.cancelled {
+				cancel()
+			}
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, fixture.expectedValue, shouldRetry(req, nil, fixture.err))
+		})
+	}
+}
+
+func TestAuthedTransport_Retry_tokenError(t *testing.T) {
+	var calls int
+	transport := &AuthedTransport{
+		TokenSource: tokenSourceFunc(func(context.Context) (*Token, error) {
+			calls++
+			return nil, errors.New("invalid client credentials")
+		}),
+		Retry: RetryPolicy{MaxRetries: 3, WaitTime: time.Millisecond, MaxWaitTime: time.Millisecond},
+	}
+	req, err := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = transport.RoundTrip(req)
+	assert.ErrorContains(t, err, "failed to get access token: invalid client credentials")
+	assert.Equal(t, 1, calls, "should not retry access token errors")
+}
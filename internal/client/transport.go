@@ -2,57 +2,246 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"log"
+	"io"
 	"net/http"
 	"os"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/lifeomic/terraform-provider-lifeomic/internal/lambda"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
-func NewAuthedTransport(authToken, accountID, serviceName string, header map[string]string) *AuthedTransport {
+// NewAuthedTransport creates an AuthedTransport for the service named by
+// config.ServiceName. When lambda mode is enabled, requests are sent to the
+// service's lambda function rather than over HTTP, and an error is returned
+// if the AWS configuration needed to invoke it is missing.
+func NewAuthedTransport(config Config) (*AuthedTransport, error) {
 	transport := &AuthedTransport{
-		AuthToken: authToken,
-		AccountID: accountID,
-		Headers:   header,
+		AuthToken:   config.AuthToken,
+		AccountID:   config.AccountID,
+		Headers:     config.Header,
+		TokenSource: config.TokenSource,
+		Retry:       config.RetryPolicy(),
+		Limiter:     config.Limiters[config.ServiceName],
+		ServiceName: config.ServiceName,
+		Timeout:     config.Timeout,
 	}
 
-	if user, ok := os.LookupEnv("LIFEOMIC_USER"); ok {
+	transport.UserID = config.UserID
+	if user, ok := os.LookupEnv(UserIDEnvVar); ok && transport.UserID == "" {
 		transport.UserID = user
 	}
 
-	if GetUseLambda() {
-		lambdaTransport, err := lambda.NewRoundTripper(context.Background(), lambda.URI{
-			Function: serviceName,
-		}, header)
+	policy := config.Policy
+	if encoded := os.Getenv(PolicyEnvVar); policy == nil && encoded != "" {
+		policy = new(PolicyDocument)
+		if err := json.Unmarshal([]byte(encoded), policy); err != nil {
+			return nil, fmt.Errorf("invalid policy in $%s: %w", PolicyEnvVar, err)
+		}
+	}
+	if policy != nil {
+		encoded, err := json.Marshal(policy)
 		if err != nil {
-			log.Fatalf("failed to create lambda transport: %s", err)
+			return nil, fmt.Errorf("failed to encode policy: %w", err)
 		}
+		transport.Policy = string(encoded)
+	}
+
+	base, err := config.BaseTransports.get(config.ServiceName, func() (http.RoundTripper, error) {
+		return newBaseTransport(config)
+	})
+	if err != nil {
+		return nil, err
+	}
+	transport.Base = base
+	return transport, nil
+}
+
+// newBaseTransport creates the transport AuthedTransport sends the requests of
+// config.ServiceName with, which logs them and sends them over HTTP or to the
+// service's lambda function.
+func newBaseTransport(config Config) (http.RoundTripper, error) {
+	base := config.Transport
+	if config.LambdaEnabled() {
+		lambdaTransport, err := lambda.NewRoundTripper(context.Background(), config.LambdaURI(), config.Header)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create lambda transport for %s: %w", config.ServiceName, err)
+		}
+
+		base = lambdaTransport
+	}
 
-		transport.Base = lambdaTransport
+	// Treat any malformed value as false.
+	debug, _ := strconv.ParseBool(os.Getenv(DebugEnvVar))
+	customHeaders := make([]string, 0, len(config.Header))
+	for name := range config.Header {
+		customHeaders = append(customHeaders, name)
+	}
+	return &LoggingTransport{
+		Base:             base,
+		LogBodies:        config.Debug || debug,
+		SensitiveHeaders: customHeaders,
+	}, nil
+}
+
+// BaseTransports caches the base transports of AuthedTransports by service
+// name. Configs which share it, e.g. the configs of several accounts, create
+// clients which send requests through the same lambda and HTTP transports
+// rather than creating their own. The zero value is ready to use, and a nil
+// BaseTransports doesn't cache transports.
+type BaseTransports struct {
+	mu         sync.Mutex
+	transports map[string]http.RoundTripper
+}
+
+// get returns the cached transport of service, calling create to create it
+// if there isn't one.
+func (b *BaseTransports) get(service string, create func() (http.RoundTripper, error)) (http.RoundTripper, error) {
+	if b == nil {
+		return create()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if transport, ok := b.transports[service]; ok {
+		return transport, nil
+	}
+	transport, err := create()
+	if err != nil {
+		return nil, err
+	}
+	if b.transports == nil {
+		b.transports = map[string]http.RoundTripper{}
 	}
-	return transport
+	b.transports[service] = transport
+	return transport, nil
+}
+
+// contextKey is the type of the context keys used by AuthedTransport.
+type contextKey int
+
+const withoutAccountKey contextKey = iota
+
+// withoutAccount returns a context whose requests AuthedTransport sends
+// without the LifeOmic-Account header.
+func withoutAccount(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withoutAccountKey, true)
 }
 
 type AuthedTransport struct {
 	AuthToken string
 	AccountID string
 	UserID    string
-	Headers   map[string]string
+	// Policy is the JSON encoded ABAC policy document sent in the
+	// LifeOmic-Policy header.
+	Policy  string
+	Headers map[string]string
+
+	// TokenSource supplies the access token when it's set, taking precedence
+	// over AuthToken. If it implements Invalidate, requests rejected with a
+	// 401 are retried once with a new token.
+	TokenSource TokenSource
+
+	// Retry is the policy for retrying idempotent requests. The zero value
+	// disables retries.
+	Retry RetryPolicy
+
+	// Limiter limits the rate of requests, and how many are in flight. Every
+	// attempt of a request, including retries, waits for it. A nil Limiter
+	// doesn't limit requests.
+	Limiter *Limiter
+
+	// ServiceName names the service in the spans of requests.
+	ServiceName string
+
+	// Timeout bounds how long each attempt of a request may take, including
+	// reading the response body. Zero disables the timeout.
+	Timeout time.Duration
 
 	Base http.RoundTripper
 }
 
+// tokenInvalidator is implemented by TokenSources which cache tokens, e.g.
+// CachingTokenSource.
+type tokenInvalidator interface {
+	Invalidate()
+}
+
 func (t *AuthedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	if t.AuthToken != "" {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", t.AuthToken))
+	return t.Retry.retryRoundTrip(req, t.authedRoundTrip)
+}
+
+// authedRoundTrip sends req, retrying once with a new token if it's rejected.
+func (t *AuthedTransport) authedRoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.roundTrip(req)
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+
+	invalidator, ok := t.TokenSource.(tokenInvalidator)
+	if !ok {
+		return res, nil
+	}
+
+	// The token may have been revoked or expired early, so retry once with a
+	// new one if the request can be replayed.
+	retry, ok := rewindRequest(req)
+	if !ok {
+		return res, nil
+	}
+	invalidator.Invalidate()
+	_, _ = io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+	return t.roundTrip(retry)
+}
+
+// tokenError is returned when a request's access token can't be fetched,
+// e.g. because the OAuth credentials are invalid.
+type tokenError struct {
+	err error
+}
+
+func (e *tokenError) Error() string {
+	return fmt.Sprintf("failed to get access token: %s", e.err)
+}
+
+func (e *tokenError) Unwrap() error {
+	return e.err
+}
+
+func (t *AuthedTransport) roundTrip(req *http.Request) (*http.Response, error) {
+	authToken := t.AuthToken
+	if t.TokenSource != nil {
+		token, err := t.TokenSource.Token(req.Context())
+		if err != nil {
+			return nil, &tokenError{err: err}
+		}
+		authToken = token.AccessToken
 	}
-	if t.AccountID != "" {
-		req.Header.Set("LifeOmic-Account", t.AccountID)
+
+	// RoundTrippers must not modify the caller's request, so the headers are
+	// set on a copy.
+	req = req.Clone(req.Context())
+	if authToken != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", authToken))
+	}
+	if omit, _ := req.Context().Value(withoutAccountKey).(bool); t.AccountID != "" && !omit {
+		req.Header.Set(accountHeader, t.AccountID)
 	}
 	if t.UserID != "" {
-		req.Header.Set("LifeOmic-User", t.UserID)
+		req.Header.Set(userHeader, t.UserID)
+	}
+	if t.Policy != "" {
+		req.Header.Set(policyHeader, t.Policy)
 	}
 
 	for k, v := range t.Headers {
@@ -63,14 +252,106 @@ func (t *AuthedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	if baseTransport == nil {
 		baseTransport = http.DefaultTransport
 	}
-	return baseTransport.RoundTrip(req)
+
+	release, err := t.Limiter.Acquire(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, span := t.startSpan(req)
+	tracing.Inject(ctx, req.Header)
+	if t.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.Timeout)
+		releaseLimiter := release
+		release = func() {
+			cancel()
+			releaseLimiter()
+		}
+	}
+
+	res, err := baseTransport.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		release()
+		tracing.EndSpan(span, err)
+		return nil, err
+	}
+
+	span.SetAttributes(semconv.HTTPStatusCodeKey.Int(res.StatusCode))
+	if res.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, http.StatusText(res.StatusCode))
+	}
+	span.End()
+
+	res.Body = &releaseBody{ReadCloser: res.Body, release: release}
+	return res, nil
+}
+
+// startSpan starts the client span of an attempt to send req.
+func (t *AuthedTransport) startSpan(req *http.Request) (context.Context, trace.Span) {
+	attributes := []attribute.KeyValue{
+		semconv.HTTPMethodKey.String(req.Method),
+		semconv.HTTPURLKey.String(req.URL.Redacted()),
+	}
+	if t.ServiceName != "" {
+		attributes = append(attributes, tracing.ServiceKey.String(t.ServiceName))
+	}
+	if account := req.Header.Get(accountHeader); account != "" {
+		attributes = append(attributes, tracing.AccountIDKey.String(account))
+	}
+	if operation := operationName(req.Context()); operation != "" {
+		attributes = append(attributes, tracing.GraphQLOperationKey.String(operation))
+	}
+
+	return tracing.StartSpan(req.Context(), "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attributes...))
+}
+
+// rewindRequest returns a copy of req which can be sent again, or false if
+// its body can't be read again.
+func rewindRequest(req *http.Request) (*http.Request, bool) {
+	retry := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return retry, true
+	}
+	if req.GetBody == nil {
+		return nil, false
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	retry.Body = body
+	return retry, true
 }
 
 func (t *AuthedTransport) Do(req *http.Request) (*http.Response, error) {
 	return t.RoundTrip(req)
 }
 
+// LambdaURI returns the lambda function to invoke for config.ServiceName.
+func (c Config) LambdaURI() lambda.URI {
+	uri, ok := c.LambdaFunctions[c.ServiceName]
+	if !ok {
+		uri = lambda.URI{Function: c.ServiceName}
+	}
+	if uri.Qualifier == "" {
+		uri.Qualifier = c.LambdaQualifier
+	}
+	return uri
+}
+
+// LambdaEnabled reports whether requests invoke the service's lambda function,
+// which is UseLambda if it's set or $LIFEOMIC_USE_LAMBDA otherwise.
+func (c Config) LambdaEnabled() bool {
+	if c.UseLambda != nil {
+		return *c.UseLambda
+	}
+	return GetUseLambda()
+}
+
 func GetUseLambda() bool {
-	useLambda, _ := strconv.ParseBool(os.Getenv("LIFEOMIC_USE_LAMBDA"))
+	useLambda, _ := strconv.ParseBool(os.Getenv(UseLambdaEnvVar))
 	return useLambda
 }
@@ -0,0 +1,278 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/lambda"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/tracing"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+)
+
+func TestConfig_LambdaURI(t *testing.T) {
+	for _, fixture := range []struct {
+		name          string
+		config        Config
+		expectedValue lambda.URI
+	}{
+		{
+			name:          "should default to the service's function",
+			config:        Config{ServiceName: "account-service"},
+			expectedValue: lambda.URI{Function: "account-service"},
+		},
+		{
+			name:          "should use the default qualifier",
+			config:        Config{ServiceName: "account-service", LambdaQualifier: "staging"},
+			expectedValue: lambda.URI{Function: "account-service", Qualifier: "staging"},
+		},
+		{
+			name: "should use mapped functions",
+			config: Config{
+				ServiceName:     "account-service",
+				LambdaQualifier: "staging",
+				LambdaFunctions: map[string]lambda.URI{
+					"account-service": {Function: "accounts-v2"},
+				},
+			},
+			expectedValue: lambda.URI{Function: "accounts-v2", Qualifier: "staging"},
+		},
+		{
+			name: "should prefer mapped qualifiers",
+			config: Config{
+				ServiceName:     "account-service",
+				LambdaQualifier: "staging",
+				LambdaFunctions: map[string]lambda.URI{
+					"account-service": {Function: "accounts-v2", Qualifier: "42"},
+				},
+			},
+			expectedValue: lambda.URI{Function: "accounts-v2", Qualifier: "42"},
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			assert.Equal(t, fixture.expectedValue, fixture.config.LambdaURI())
+		})
+	}
+}
+
+func TestNewAuthedTransport_identity(t *testing.T) {
+	for _, fixture := range []struct {
+		name           string
+		config         Config
+		env            map[string]string
+		expectedUser   string
+		expectedPolicy string
+		expectedErr    string
+	}{
+		{
+			name: "should send the user and policy",
+			config: Config{
+				UserID: "johndoe",
+				Policy: &PolicyDocument{Rules: PolicyRules{"readData": StaticRule(true)}},
+			},
+			expectedUser:   "johndoe",
+			expectedPolicy: `{"rules":{"readData":true}}`,
+		},
+		{
+			name:           "should default to environment variables",
+			env:            map[string]string{UserIDEnvVar: "janedoe", PolicyEnvVar: `{"rules": {"writeData": true}}`},
+			expectedUser:   "janedoe",
+			expectedPolicy: `{"rules":{"writeData":true}}`,
+		},
+		{
+			name:         "should prefer the config to environment variables",
+			config:       Config{UserID: "johndoe"},
+			env:          map[string]string{UserIDEnvVar: "janedoe"},
+			expectedUser: "johndoe",
+		},
+		{
+			name:        "should reject invalid policies",
+			env:         map[string]string{PolicyEnvVar: `{"rules": {"readData": "yes"}}`},
+			expectedErr: "invalid policy in $LIFEOMIC_POLICY",
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			t.Setenv(UserIDEnvVar, fixture.env[UserIDEnvVar])
+			t.Setenv(PolicyEnvVar, fixture.env[PolicyEnvVar])
+			t.Setenv(UseLambdaEnvVar, "")
+
+			transport, err := NewAuthedTransport(fixture.config)
+			if err != nil {
+				if fixture.expectedErr == "" {
+					t.Errorf("unexpected error: %s", err)
+					return
+				}
+				assert.ErrorContains(t, err, fixture.expectedErr)
+				return
+			} else if fixture.expectedErr != "" {
+				t.Errorf("expected error matching: %s; got nil", fixture.expectedErr)
+				return
+			}
+
+			var header http.Header
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				header = r.Header
+			}))
+			t.Cleanup(server.Close)
+
+			res, err := (&http.Client{Transport: transport}).Get(server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+
+			assert.Equal(t, fixture.expectedUser, header.Get(userHeader))
+			assert.Equal(t, fixture.expectedPolicy, header.Get(policyHeader))
+		})
+	}
+}
+
+func TestAuthedTransport_tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(server.Close)
+
+	req, err := http.NewRequestWithContext(WithOperationName(context.Background(), "GetPolicy"), http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport := &AuthedTransport{AccountID: "my-account", ServiceName: "account-service"}
+	res, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	spans := recorder.Ended()
+	if !assert.Len(t, spans, 1) {
+		return
+	}
+	span := spans[0]
+	assert.Equal(t, "HTTP GET", span.Name())
+	assert.Equal(t, codes.Error, span.Status().Code)
+	assert.Contains(t, span.Attributes(), tracing.AccountIDKey.String("my-account"))
+	assert.Contains(t, span.Attributes(), tracing.ServiceKey.String("account-service"))
+	assert.Contains(t, span.Attributes(), tracing.GraphQLOperationKey.String("GetPolicy"))
+	assert.Contains(t, span.Attributes(), semconv.HTTPStatusCodeKey.Int(http.StatusNotFound))
+	assert.Contains(t, traceparent, span.SpanContext().SpanID().String(), "should propagate the span")
+}
+
+func TestAuthedTransport_headers(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+	}))
+	t.Cleanup(server.Close)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "application/json")
+	transport := &AuthedTransport{
+		AuthToken: "my-token",
+		AccountID: "my-account",
+		UserID:    "johndoe",
+		Headers:   map[string]string{"X-Custom": "value"},
+	}
+	res, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	assert.Equal(t, "Bearer my-token", header.Get("Authorization"))
+	assert.Equal(t, "my-account", header.Get(accountHeader))
+	assert.Equal(t, "johndoe", header.Get(userHeader))
+	assert.Equal(t, "value", header.Get("X-Custom"))
+	assert.Equal(t, "application/json", header.Get("Accept"))
+	assert.Equal(t, http.Header{"Accept": {"application/json"}}, req.Header, "should not modify the caller's request")
+}
+
+func TestAuthedTransport_Timeout(t *testing.T) {
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			<-r.Context().Done()
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport := &AuthedTransport{
+		Timeout: 50 * time.Millisecond,
+		Retry:   RetryPolicy{MaxRetries: 1, WaitTime: time.Millisecond, MaxWaitTime: time.Millisecond},
+	}
+	res, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	assert.Equal(t, http.StatusNoContent, res.StatusCode, "should retry attempts which timed out")
+	assert.Equal(t, 2, attempts)
+}
+
+func TestNewAuthedTransport_baseTransports(t *testing.T) {
+	t.Setenv("AWS_REGION", "us-east-1")
+	useLambda := true
+	baseTransports := new(BaseTransports)
+	config := Config{ServiceName: "account-service", UseLambda: &useLambda, BaseTransports: baseTransports}
+
+	transport, err := NewAuthedTransport(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if base, ok := transport.Base.(*LoggingTransport); assert.True(t, ok) {
+		assert.IsType(t, &lambda.RoundTripper{}, base.Base)
+	}
+
+	config.AccountID = "other-account"
+	other, err := NewAuthedTransport(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "other-account", other.AccountID)
+	assert.Same(t, transport.Base, other.Base, "should share the transport of the service")
+
+	config.ServiceName = "marketplace-service"
+	service, err := NewAuthedTransport(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotSame(t, transport.Base, service.Base, "should create a transport for each service")
+
+	config.ServiceName = "account-service"
+	config.BaseTransports = nil
+	uncached, err := NewAuthedTransport(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotSame(t, transport.Base, uncached.Base, "should not cache transports without BaseTransports")
+}
@@ -9,8 +9,9 @@ import (
 )
 
 const (
-	appStoreServiceName     = "app-store-service"
-	appStoreDefaultEndpoint = "https://api.us.lifeomic.com/api/v1/graphql"
+	appStoreServiceName = "app-store-service"
+	appStoreSubdomain   = "api"
+	appStorePath        = "/api/v1/graphql"
 )
 
 type AppStoreService interface {
@@ -40,7 +41,15 @@ func (a *appStoreClient) EditAppStoreListing(ctx context.Context, id string, edi
 	return EditAppStoreListing(ctx, a.client, id, edits)
 }
 
-func NewAppStoreClient(authToken string, accountID string, header map[string]string) AppStoreService {
-	transport := client.NewAuthedTransport(authToken, accountID, appStoreServiceName, header)
-	return &appStoreClient{client: graphql.NewClient(appStoreDefaultEndpoint, transport)}
+func NewAppStoreClient(config client.Config) (AppStoreService, error) {
+	config.ServiceName = appStoreServiceName
+	transport, err := client.NewAuthedTransport(config)
+	if err != nil {
+		return nil, err
+	}
+	endpoint, err := config.ServiceURL(appStoreSubdomain, appStorePath)
+	if err != nil {
+		return nil, err
+	}
+	return &appStoreClient{client: newGraphQLClient(endpoint, transport)}, nil
 }
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	goast "go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// cursorVariable is the variable paginated queries take the cursor of the
+// page to fetch in.
+const cursorVariable = "after"
+
+// connection describes a query which returns a Relay connection, i.e. a
+// type with edges { node } and pageInfo { endCursor hasNextPage } fields, and
+// takes the cursor of the page to fetch in its "after" variable.
+type connection struct {
+	// Field is the Go name of the response field holding the connection.
+	Field string
+	// NodeType is the Go type of the connection's nodes.
+	NodeType string
+}
+
+// goStructs maps the names of the structs declared in a Go file to their
+// types.
+type goStructs map[string]*goast.StructType
+
+// parseGoStructs reads the structs declared in the Go file filename, which
+// is used to find the types genqlient generated for a query's response.
+func parseGoStructs(filename string) (goStructs, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), filename, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	structs := goStructs{}
+	goast.Inspect(file, func(n goast.Node) bool {
+		if spec, ok := n.(*goast.TypeSpec); ok {
+			if structType, ok := spec.Type.(*goast.StructType); ok {
+				structs[spec.Name.Name] = structType
+			}
+		}
+		return true
+	})
+	return structs, nil
+}
+
+// fieldType returns the type of the named struct's field.
+func (s goStructs) fieldType(structName, fieldName string) (goast.Expr, error) {
+	structType, ok := s[structName]
+	if !ok {
+		return nil, fmt.Errorf("struct %s not found", structName)
+	}
+	for _, field := range structType.Fields.List {
+		for _, name := range field.Names {
+			if name.Name == fieldName {
+				return field.Type, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("struct %s has no field %s", structName, fieldName)
+}
+
+// typeName returns the name of the type expr refers to, ignoring pointers
+// and slices.
+func typeName(expr goast.Expr) string {
+	switch t := expr.(type) {
+	case *goast.StarExpr:
+		return typeName(t.X)
+	case *goast.ArrayType:
+		return typeName(t.Elt)
+	case *goast.Ident:
+		return t.Name
+	default:
+		return types.ExprString(expr)
+	}
+}
+
+func upperFirst(s string) string {
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// hasField reports whether selections select the unaliased fields at path.
+func hasField(selections ast.SelectionSet, path ...string) bool {
+	for _, selection := range selections {
+		field, ok := selection.(*ast.Field)
+		if !ok || field.Name != path[0] || field.Alias != field.Name {
+			continue
+		}
+		if len(path) == 1 || hasField(field.SelectionSet, path[1:]...) {
+			return true
+		}
+	}
+	return false
+}
+
+// findConnection returns the connection operation returns, or nil if it
+// doesn't return a connection. The Go types genqlient generated for the
+// operation are looked up in structs.
+func findConnection(operation *ast.OperationDefinition, structs goStructs) (*connection, error) {
+	if operation.Operation != ast.Query ||
+		operation.VariableDefinitions.ForName(cursorVariable) == nil ||
+		len(operation.SelectionSet) != 1 {
+		return nil, nil
+	}
+
+	root, ok := operation.SelectionSet[0].(*ast.Field)
+	if !ok ||
+		!hasField(root.SelectionSet, "edges", "node") ||
+		!hasField(root.SelectionSet, "pageInfo", "endCursor") ||
+		!hasField(root.SelectionSet, "pageInfo", "hasNextPage") {
+		return nil, nil
+	}
+
+	field := upperFirst(root.Alias)
+	connectionType, err := structs.fieldType(operation.Name+"Response", field)
+	if err != nil {
+		return nil, err
+	}
+	edgesType, err := structs.fieldType(typeName(connectionType), "Edges")
+	if err != nil {
+		return nil, err
+	}
+	nodeType, err := structs.fieldType(typeName(edgesType), "Node")
+	if err != nil {
+		return nil, err
+	}
+	return &connection{Field: field, NodeType: types.ExprString(nodeType)}, nil
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+const testSchema = `
+type Query {
+  things(first: Int, after: String): ThingConnection!
+  project(id: ID!): Project
+}
+
+type Mutation {
+  archiveThings(after: String): ThingConnection!
+}
+
+type Project {
+  id: ID!
+  members(after: String): ThingConnection!
+}
+
+type ThingConnection {
+  edges: [ThingEdge!]!
+  pageInfo: PageInfo!
+}
+
+type ThingEdge {
+  node: Thing!
+}
+
+type PageInfo {
+  endCursor: String
+  hasNextPage: Boolean!
+}
+
+type Thing {
+  id: ID!
+}
+`
+
+// testGeneratedFile mimics the types genqlient generates for the connection
+// operations in TestFindConnection.
+const testGeneratedFile = `package gqlclient
+
+type ListThingsResponse struct {
+	Things ListThingsThingsThingConnection ` + "`json:\"things\"`" + `
+}
+
+type ListThingsThingsThingConnection struct {
+	Edges    []*ListThingsThingsThingConnectionEdgesThingEdge ` + "`json:\"edges\"`" + `
+	PageInfo ListThingsThingsThingConnectionPageInfo          ` + "`json:\"pageInfo\"`" + `
+}
+
+type ListThingsThingsThingConnectionEdgesThingEdge struct {
+	Node ListThingsThingsThingConnectionEdgesThingEdgeNodeThing ` + "`json:\"node\"`" + `
+}
+
+type ListAliasedThingsResponse struct {
+	Items ListAliasedThingsItemsThingConnection ` + "`json:\"items\"`" + `
+}
+
+type ListAliasedThingsItemsThingConnection struct {
+	Edges []ListAliasedThingsItemsThingConnectionEdgesThingEdge ` + "`json:\"edges\"`" + `
+}
+
+type ListAliasedThingsItemsThingConnectionEdgesThingEdge struct {
+	Node *ListAliasedThingsItemsThingConnectionEdgesThingEdgeNodeThing ` + "`json:\"node\"`" + `
+}
+
+type ListEdgelessThingsResponse struct {
+	Things ListEdgelessThingsThingsThingConnection ` + "`json:\"things\"`" + `
+}
+
+type ListEdgelessThingsThingsThingConnection struct {
+	PageInfo ListEdgelessThingsThingsThingConnectionPageInfo ` + "`json:\"pageInfo\"`" + `
+}
+`
+
+// loadTestOperation parses and validates a single operation against
+// testSchema.
+func loadTestOperation(t *testing.T, query string) *ast.OperationDefinition {
+	t.Helper()
+	schema := gqlparser.MustLoadSchema(&ast.Source{Name: "schema.graphql", Input: testSchema})
+	document, errs := gqlparser.LoadQuery(schema, query)
+	if errs != nil {
+		t.Fatalf("invalid operation: %s", errs)
+	}
+	if len(document.Operations) != 1 {
+		t.Fatalf("expected one operation, got %d", len(document.Operations))
+	}
+	return document.Operations[0]
+}
+
+// loadTestStructs parses the structs of testGeneratedFile.
+func loadTestStructs(t *testing.T) goStructs {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "generated.go")
+	if err := os.WriteFile(filename, []byte(testGeneratedFile), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	structs, err := parseGoStructs(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return structs
+}
+
+func TestFindConnection(t *testing.T) {
+	structs := loadTestStructs(t)
+
+	for _, fixture := range []struct {
+		name          string
+		query         string
+		expectedValue *connection
+		expectedErr   string
+	}{
+		{
+			name: "should detect connections with an after variable",
+			query: `query ListThings($first: Int, $after: String) {
+  things(first: $first, after: $after) {
+    edges { node { id } }
+    pageInfo { endCursor hasNextPage }
+  }
+}`,
+			expectedValue: &connection{
+				Field:    "Things",
+				NodeType: "ListThingsThingsThingConnectionEdgesThingEdgeNodeThing",
+			},
+		},
+		{
+			name: "should use the alias of the connection field",
+			query: `query ListAliasedThings($after: String) {
+  items: things(after: $after) {
+    edges { node { id } }
+    pageInfo { endCursor hasNextPage }
+  }
+}`,
+			expectedValue: &connection{
+				Field:    "Items",
+				NodeType: "*ListAliasedThingsItemsThingConnectionEdgesThingEdgeNodeThing",
+			},
+		},
+		{
+			name: "should ignore connections without pageInfo.endCursor",
+			query: `query ListThings($after: String) {
+  things(after: $after) {
+    edges { node { id } }
+    pageInfo { hasNextPage }
+  }
+}`,
+		},
+		{
+			name: "should ignore connections without pageInfo.hasNextPage",
+			query: `query ListThings($after: String) {
+  things(after: $after) {
+    edges { node { id } }
+    pageInfo { endCursor }
+  }
+}`,
+		},
+		{
+			name: "should ignore aliased pageInfo fields",
+			query: `query ListThings($after: String) {
+  things(after: $after) {
+    edges { node { id } }
+    pageInfo { cursor: endCursor hasNextPage }
+  }
+}`,
+		},
+		{
+			name: "should ignore operations without an after variable",
+			query: `query ListThings($first: Int) {
+  things(first: $first) {
+    edges { node { id } }
+    pageInfo { endCursor hasNextPage }
+  }
+}`,
+		},
+		{
+			name: "should ignore nested connections",
+			query: `query GetProject($id: ID!, $after: String) {
+  project(id: $id) {
+    members(after: $after) {
+      edges { node { id } }
+      pageInfo { endCursor hasNextPage }
+    }
+  }
+}`,
+		},
+		{
+			name: "should ignore operations selecting several fields",
+			query: `query ListThings($id: ID!, $after: String) {
+  things(after: $after) {
+    edges { node { id } }
+    pageInfo { endCursor hasNextPage }
+  }
+  project(id: $id) { id }
+}`,
+		},
+		{
+			name: "should ignore mutations",
+			query: `mutation ArchiveThings($after: String) {
+  archiveThings(after: $after) {
+    edges { node { id } }
+    pageInfo { endCursor hasNextPage }
+  }
+}`,
+		},
+		{
+			name: "should reject connections without a response type",
+			query: `query ListOtherThings($after: String) {
+  things(after: $after) {
+    edges { node { id } }
+    pageInfo { endCursor hasNextPage }
+  }
+}`,
+			expectedErr: "struct ListOtherThingsResponse not found",
+		},
+		{
+			name: "should reject connection types without edges",
+			query: `query ListEdgelessThings($after: String) {
+  things(after: $after) {
+    edges { node { id } }
+    pageInfo { endCursor hasNextPage }
+  }
+}`,
+			expectedErr: "struct ListEdgelessThingsThingsThingConnection has no field Edges",
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			value, err := findConnection(loadTestOperation(t, fixture.query), structs)
+			if err != nil {
+				if fixture.expectedErr == "" {
+					t.Errorf("unexpected error: %s", err)
+					return
+				}
+				assert.EqualError(t, err, fixture.expectedErr)
+				return
+			} else if fixture.expectedErr != "" {
+				t.Errorf("expected error matching: %s; got nil", fixture.expectedErr)
+				return
+			}
+
+			assert.Equal(t, fixture.expectedValue, value)
+		})
+	}
+}
+
+func TestGenerateServiceFile_connection(t *testing.T) {
+	operation := loadTestOperation(t, `query ListThings($first: Int, $after: String) {
+  things(first: $first, after: $after) {
+    edges { node { id } }
+    pageInfo { endCursor hasNextPage }
+  }
+}`)
+	c := client{Name: "things", ServiceName: "things-service", Subdomain: "things", BasePath: "/v1/things"}
+
+	var out bytes.Buffer
+	document := &ast.QueryDocument{Operations: ast.OperationList{operation}}
+	if err := generateServiceFile("gqlclient", c, document, loadTestStructs(t), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	generated := out.String()
+	assert.Contains(t, generated, "ListThings(ctx context.Context, first int, after string) (*ListThingsResponse, error)")
+	assert.Contains(t, generated, "AllListThings(ctx context.Context, first int) ([]ListThingsThingsThingConnectionEdgesThingEdgeNodeThing, error)")
+	assert.Contains(t, generated, "return res.Things.PageInfo.EndCursor, res.Things.PageInfo.HasNextPage, nil")
+}
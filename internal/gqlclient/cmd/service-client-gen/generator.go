@@ -37,8 +37,12 @@ func (s client) Reciever() string {
 	return fmt.Sprintf("%s *%s", s.RecieverName(), s.StructName())
 }
 
-func (s client) EndpointConstName() string {
-	return fmt.Sprintf("%sDefaultEndpoint", s.LowercaseName())
+func (s client) SubdomainConstName() string {
+	return fmt.Sprintf("%sSubdomain", s.LowercaseName())
+}
+
+func (s client) PathConstName() string {
+	return fmt.Sprintf("%sPath", s.LowercaseName())
 }
 
 func (s client) ServiceConstName() string {
@@ -65,7 +69,14 @@ func Generate(cfg config) error {
 	// For each service (name and filename) parse the queries and generate
 	// a Go file containing an interface with all related queries, a struct
 	// which implements the interface, and finally a factory function that
-	// returns an instance of said struct.
+	// returns an instance of said struct. Queries returning connections
+	// also get a method which fetches every page, using the response types
+	// genqlient generated to find the type of the connection's nodes.
+	structs, err := parseGoStructs(cfg.GeneratedFile)
+	if err != nil {
+		return fmt.Errorf("failed to read genqlient types: %w", err)
+	}
+
 	for _, c := range cfg.Clients {
 		log.Printf("generating for service %s (%s)...\n", c.Name, c.GoFile)
 
@@ -89,7 +100,7 @@ func Generate(cfg config) error {
 			return fmt.Errorf("could not open file %q for writing: %w", c.GQLFile, err)
 		}
 
-		if err := generateServiceFile(cfg.PackageName, c, document, w); err != nil {
+		if err := generateServiceFile(cfg.PackageName, c, document, structs, w); err != nil {
 			return fmt.Errorf("failed to generate %q service: %w", c.Name, err)
 		}
 	}
@@ -106,17 +117,55 @@ func writeOperationFunctionSignature(w io.Writer, operation *ast.OperationDefini
 	fmt.Fprintf(w, ") (*%sResponse, error)", operation.Name)
 }
 
-func writeInterface(w io.Writer, c client, operations []*ast.OperationDefinition) {
+// writeAllFunctionSignature writes the signature of the method which fetches
+// every page of a connection. It takes the operation's variables except for
+// the cursor, and returns the connection's nodes.
+func writeAllFunctionSignature(w io.Writer, operation *ast.OperationDefinition, conn *connection) {
+	fmt.Fprintf(w, "All%s(ctx context.Context ", operation.Name)
+	for _, variable := range operation.VariableDefinitions {
+		if variable.Variable != cursorVariable {
+			fmt.Fprintf(w, ", %s %s", variable.Variable, gqlScalarTypeToGo(variable.Type.Name()))
+		}
+	}
+	fmt.Fprintf(w, ") ([]%s, error)", conn.NodeType)
+}
+
+func writeInterface(w io.Writer, c client, operations []*ast.OperationDefinition, connections map[string]*connection) {
 	fmt.Fprintf(w, "type %s interface {\n", c.InterfaceName())
 	for _, operation := range operations {
 		fmt.Fprint(w, "\t")
 		writeOperationFunctionSignature(w, operation)
 		fmt.Fprintln(w)
+		if conn, ok := connections[operation.Name]; ok {
+			fmt.Fprintf(w, "\t// All%s fetches every page of %s, following\n", operation.Name, operation.Name)
+			fmt.Fprint(w, "\t// pageInfo.endCursor, and returns all of the nodes.\n\t")
+			writeAllFunctionSignature(w, operation, conn)
+			fmt.Fprintln(w)
+		}
 	}
 	fmt.Fprintf(w, "}\n")
 }
 
-func writeStruct(w io.Writer, c client, operations []*ast.OperationDefinition) {
+func writeAllFunction(w io.Writer, c client, operation *ast.OperationDefinition, conn *connection) {
+	fmt.Fprintf(w, "func (%s) ", c.Reciever())
+	writeAllFunctionSignature(w, operation, conn)
+	fmt.Fprintln(w, " {")
+	fmt.Fprintf(w, "\tvar nodes []%s\n", conn.NodeType)
+	fmt.Fprintf(w, "\terr := paginate(ctx, func(%s string) (string, bool, error) {\n", cursorVariable)
+	fmt.Fprintf(w, "\t\tres, err := %s(ctx, %s.client, ", operation.Name, c.RecieverName())
+	for _, variable := range operation.VariableDefinitions {
+		fmt.Fprintf(w, "%s, ", variable.Variable)
+	}
+	fmt.Fprint(w, ")\n")
+	fmt.Fprint(w, "\t\tif err != nil {\n\t\t\treturn \"\", false, err\n\t\t}\n")
+	fmt.Fprintf(w, "\t\tfor _, edge := range res.%s.Edges {\n", conn.Field)
+	fmt.Fprint(w, "\t\t\tnodes = append(nodes, edge.Node)\n\t\t}\n")
+	fmt.Fprintf(w, "\t\treturn res.%s.PageInfo.EndCursor, res.%s.PageInfo.HasNextPage, nil\n", conn.Field, conn.Field)
+	fmt.Fprint(w, "\t})\n")
+	fmt.Fprint(w, "\treturn nodes, err\n}\n\n")
+}
+
+func writeStruct(w io.Writer, c client, operations []*ast.OperationDefinition, connections map[string]*connection) {
 	fmt.Fprintf(w, "type %s struct{\n\tclient graphql.Client\n}\n", c.StructName())
 	for _, operation := range operations {
 		fmt.Fprintf(w, "func (%s) ", c.Reciever())
@@ -127,20 +176,28 @@ func writeStruct(w io.Writer, c client, operations []*ast.OperationDefinition) {
 			fmt.Fprintf(w, "%s, ", variable.Variable)
 		}
 		fmt.Fprint(w, ")\n}\n\n")
+		if conn, ok := connections[operation.Name]; ok {
+			writeAllFunction(w, c, operation, conn)
+		}
 	}
 }
 
 func writeFactory(w io.Writer, c client) {
-	fmt.Fprintf(w, "func New%sClient(authToken string, accountID string, header map[string]string) %s {\n", c.UppercaseName(), c.InterfaceName())
-	fmt.Fprintf(w, "\ttransport := client.NewAuthedTransport(authToken, accountID, %s, header)\n", c.ServiceConstName())
-	fmt.Fprintf(w, "\treturn &%s{client: graphql.NewClient(%s, transport)}\n", c.StructName(), c.EndpointConstName())
+	fmt.Fprintf(w, "func New%sClient(config client.Config) (%s, error) {\n", c.UppercaseName(), c.InterfaceName())
+	fmt.Fprintf(w, "\tconfig.ServiceName = %s\n", c.ServiceConstName())
+	fmt.Fprint(w, "\ttransport, err := client.NewAuthedTransport(config)\n")
+	fmt.Fprint(w, "\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+	fmt.Fprintf(w, "\tendpoint, err := config.ServiceURL(%s, %s)\n", c.SubdomainConstName(), c.PathConstName())
+	fmt.Fprint(w, "\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+	fmt.Fprintf(w, "\treturn &%s{client: newGraphQLClient(endpoint, transport)}, nil\n", c.StructName())
 	fmt.Fprint(w, "}\n\n")
 }
 
 func writeConstants(w io.Writer, c client) {
 	fmt.Fprintln(w, "const (")
 	fmt.Fprintf(w, "\t%s = \"%s\"\n", c.ServiceConstName(), c.ServiceName)
-	fmt.Fprintf(w, "\t%s = \"%s/graphql\"\n", c.EndpointConstName(), c.BaseURL)
+	fmt.Fprintf(w, "\t%s = \"%s\"\n", c.SubdomainConstName(), c.Subdomain)
+	fmt.Fprintf(w, "\t%s = \"%s/graphql\"\n", c.PathConstName(), c.BasePath)
 	fmt.Fprint(w, ")\n\n")
 }
 
@@ -148,7 +205,18 @@ func writeImports(w io.Writer, pkgs ...string) {
 	fmt.Fprintf(w, "import(\n\t\"%s\"\n)\n\n", strings.Join(pkgs, "\"\n\t\""))
 }
 
-func generateServiceFile(packageName string, c client, document *ast.QueryDocument, file io.Writer) error {
+func generateServiceFile(packageName string, c client, document *ast.QueryDocument, structs goStructs, file io.Writer) error {
+	connections := map[string]*connection{}
+	for _, operation := range document.Operations {
+		conn, err := findConnection(operation, structs)
+		if err != nil {
+			return fmt.Errorf("failed to find the connection returned by %s: %w", operation.Name, err)
+		}
+		if conn != nil {
+			connections[operation.Name] = conn
+		}
+	}
+
 	fileBuf := new(bytes.Buffer)
 
 	fmt.Fprintf(fileBuf, "package %s\n// Generated by ./cmd/service-client-gen\n\n", packageName)
@@ -158,9 +226,9 @@ func generateServiceFile(packageName string, c client, document *ast.QueryDocume
 		"github.com/lifeomic/terraform-provider-lifeomic/internal/client")
 	writeConstants(fileBuf, c)
 
-	writeInterface(fileBuf, c, document.Operations)
+	writeInterface(fileBuf, c, document.Operations, connections)
 	fmt.Fprintln(fileBuf)
-	writeStruct(fileBuf, c, document.Operations)
+	writeStruct(fileBuf, c, document.Operations, connections)
 	fmt.Fprintln(fileBuf)
 	writeFactory(fileBuf, c)
 	fmt.Fprintln(fileBuf)
@@ -10,14 +10,18 @@ import (
 type client struct {
 	Name        string `yaml:"name"`
 	ServiceName string `yaml:"serviceName"`
-	BaseURL     string `yaml:"baseURL"`
+	Subdomain   string `yaml:"subdomain"`
+	BasePath    string `yaml:"basePath"`
 	GQLFile     string `yaml:"gqlFile"`
 	GoFile      string `yaml:"goFile"`
 }
 
 type config struct {
-	PackageName string   `yaml:"packageName"`
-	Clients     []client `yaml:"clients"`
+	PackageName string `yaml:"packageName"`
+	// GeneratedFile is the file generated by genqlient, which is read to
+	// find the types of the nodes of paginated queries.
+	GeneratedFile string   `yaml:"generatedFile"`
+	Clients       []client `yaml:"clients"`
 }
 
 func main() {
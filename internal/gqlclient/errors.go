@@ -0,0 +1,200 @@
+package gqlclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Khan/genqlient/graphql"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/client"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// ErrorCode classifies a GraphQL error. It's read from the code extension
+// of the error.
+type ErrorCode string
+
+// ErrorCode constants
+const (
+	ErrorCodeNotFound     ErrorCode = "NOT_FOUND"
+	ErrorCodeForbidden    ErrorCode = "FORBIDDEN"
+	ErrorCodeBadUserInput ErrorCode = "BAD_USER_INPUT"
+)
+
+// Error is an error in the response of a GraphQL operation.
+type Error struct {
+	// Operation is the name of the operation which failed.
+	Operation string
+	Message   string
+	// Path is the path of the response field the error occurred in, e.g.
+	// "createDraftModule".
+	Path string
+	Code ErrorCode
+	// Fields are the dot separated paths of the input fields which caused
+	// a BAD_USER_INPUT error, if the service reports them, e.g.
+	// "input.sourceInfo.imageUrl".
+	Fields []string
+	// Extensions are all of the error's extensions.
+	Extensions map[string]any
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s", e.Operation, e.Message)
+	if e.Code != "" {
+		fmt.Fprintf(&b, " [%s]", e.Code)
+	}
+	if e.Path != "" {
+		fmt.Fprintf(&b, " at %s", e.Path)
+	}
+	return b.String()
+}
+
+// Errors are the errors in the response of a GraphQL operation. Use
+// errors.As, or the IsNotFound, IsForbidden, and IsBadUserInput helpers, to
+// inspect them.
+type Errors []*Error
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// newErrors converts the errors of a GraphQL response into Errors.
+func newErrors(operation string, list gqlerror.List) Errors {
+	errs := make(Errors, len(list))
+	for i, gqlErr := range list {
+		err := &Error{
+			Operation:  operation,
+			Message:    gqlErr.Message,
+			Extensions: gqlErr.Extensions,
+		}
+		if len(gqlErr.Path) != 0 {
+			err.Path = gqlErr.Path.String()
+		}
+		if code, ok := gqlErr.Extensions["code"].(string); ok {
+			err.Code = ErrorCode(code)
+		}
+		err.Fields = errorFields(gqlErr.Extensions)
+		errs[i] = err
+	}
+	return errs
+}
+
+// errorFields returns the input fields reported by an error's field, fields,
+// or invalidArgs extensions.
+func errorFields(extensions map[string]any) []string {
+	var fields []string
+	for _, key := range []string{"field", "fields", "invalidArgs"} {
+		switch value := extensions[key].(type) {
+		case string:
+			fields = append(fields, value)
+		case []any:
+			for _, field := range value {
+				if field, ok := field.(string); ok {
+					fields = append(fields, field)
+				}
+			}
+		}
+	}
+	return fields
+}
+
+// IsNotFound reports whether err is a NOT_FOUND GraphQL error, or an HTTP 404
+// response.
+func IsNotFound(err error) bool {
+	return HasCode(err, ErrorCodeNotFound) || client.IsNotFound(err)
+}
+
+// IsForbidden reports whether err is a FORBIDDEN GraphQL error, or an HTTP
+// 403 response.
+func IsForbidden(err error) bool {
+	return HasCode(err, ErrorCodeForbidden) || client.IsForbidden(err)
+}
+
+// IsBadUserInput reports whether err is a BAD_USER_INPUT GraphQL error.
+func IsBadUserInput(err error) bool {
+	return HasCode(err, ErrorCodeBadUserInput)
+}
+
+// HasCode reports whether err is, or contains, a GraphQL error with the
+// given code.
+func HasCode(err error, code ErrorCode) bool {
+	var errs Errors
+	if errors.As(err, &errs) {
+		for _, e := range errs {
+			if e.Code == code {
+				return true
+			}
+		}
+		return false
+	}
+
+	var e *Error
+	return errors.As(err, &e) && e.Code == code
+}
+
+// errorClient is a graphql.Client returning Errors for responses with
+// GraphQL errors. It also marks queries as idempotent, so that
+// AuthedTransport retries them, and names the operation in request logs.
+type errorClient struct {
+	graphql.Client
+}
+
+// newGraphQLClient creates the graphql.Client used by the generated service
+// clients. Errors are returned as Errors, or as *client.APIError for
+// responses with an HTTP error status and no GraphQL errors.
+func newGraphQLClient(endpoint string, doer graphql.Doer) graphql.Client {
+	return &errorClient{Client: graphql.NewClient(endpoint, &statusDoer{doer: doer})}
+}
+
+func (c *errorClient) MakeRequest(ctx context.Context, req *graphql.Request, resp *graphql.Response) error {
+	ctx = client.WithOperationName(ctx, req.OpName)
+	// Queries can be retried, unlike mutations.
+	if strings.HasPrefix(strings.TrimSpace(req.Query), "query") {
+		ctx = client.WithIdempotent(ctx)
+	}
+
+	err := c.Client.MakeRequest(ctx, req, resp)
+
+	var list gqlerror.List
+	if errors.As(err, &list) {
+		return newErrors(req.OpName, list)
+	}
+	return err
+}
+
+// statusDoer returns the GraphQL errors of responses with an HTTP error
+// status, e.g. 400 responses to invalid input, or a *client.APIError if there
+// are none, rather than leaving graphql.Client to flatten them into a string.
+type statusDoer struct {
+	doer graphql.Doer
+}
+
+func (d *statusDoer) Do(req *http.Request) (*http.Response, error) {
+	res, err := d.doer.Do(req)
+	if err != nil || res.StatusCode == http.StatusOK {
+		return res, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read error response: %w", err)
+	}
+
+	var graphQLRes struct {
+		Errors gqlerror.List `json:"errors"`
+	}
+	if json.Unmarshal(body, &graphQLRes) == nil && len(graphQLRes.Errors) != 0 {
+		return nil, graphQLRes.Errors
+	}
+	return nil, client.NewAPIError(res, body)
+}
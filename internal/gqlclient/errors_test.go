@@ -0,0 +1,124 @@
+package gqlclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrors(t *testing.T) {
+	t.Setenv(client.UseLambdaEnvVar, "")
+
+	for _, fixture := range []struct {
+		name          string
+		statusCode    int
+		body          string
+		expectedValue Errors
+		expectedErr   string
+		expectedIs    string
+	}{
+		{
+			name:       "should parse bad user input errors",
+			statusCode: http.StatusOK,
+			body: `{"data": null, "errors": [{
+				"message": "imageUrl must be a URL",
+				"path": ["setWellnessOfferingDraftModuleSource"],
+				"extensions": {"code": "BAD_USER_INPUT", "field": "input.sourceInfo.imageUrl"}
+			}]}`,
+			expectedValue: Errors{{
+				Operation:  "GetWellnessOfferingModule",
+				Message:    "imageUrl must be a URL",
+				Path:       "setWellnessOfferingDraftModuleSource",
+				Code:       ErrorCodeBadUserInput,
+				Fields:     []string{"input.sourceInfo.imageUrl"},
+				Extensions: map[string]any{"code": "BAD_USER_INPUT", "field": "input.sourceInfo.imageUrl"},
+			}},
+			expectedErr: "GetWellnessOfferingModule: imageUrl must be a URL [BAD_USER_INPUT] at setWellnessOfferingDraftModuleSource",
+			expectedIs:  "IsBadUserInput",
+		},
+		{
+			name:       "should parse not found errors",
+			statusCode: http.StatusOK,
+			body: `{"data": null, "errors": [
+				{"message": "Module not found", "path": ["myModule"], "extensions": {"code": "NOT_FOUND"}},
+				{"message": "Something else"}
+			]}`,
+			expectedErr: "GetWellnessOfferingModule: Module not found [NOT_FOUND] at myModule; GetWellnessOfferingModule: Something else",
+			expectedIs:  "IsNotFound",
+		},
+		{
+			name:       "should parse GraphQL errors of HTTP error responses",
+			statusCode: http.StatusBadRequest,
+			body: `{"errors": [{
+				"message": "Variable \"$id\" of required type \"ID!\" was not provided.",
+				"extensions": {"code": "BAD_USER_INPUT"}
+			}]}`,
+			expectedValue: Errors{{
+				Operation:  "GetWellnessOfferingModule",
+				Message:    `Variable "$id" of required type "ID!" was not provided.`,
+				Code:       ErrorCodeBadUserInput,
+				Extensions: map[string]any{"code": "BAD_USER_INPUT"},
+			}},
+			expectedErr: `GetWellnessOfferingModule: Variable "$id" of required type "ID!" was not provided. [BAD_USER_INPUT]`,
+			expectedIs:  "IsBadUserInput",
+		},
+		{
+			name:        "should return HTTP errors as API errors",
+			statusCode:  http.StatusForbidden,
+			body:        `{"message": "Forbidden"}`,
+			expectedErr: "POST /: 403 Forbidden: Forbidden",
+			expectedIs:  "IsForbidden",
+		},
+		{
+			name:        "should classify HTTP not found errors",
+			statusCode:  http.StatusNotFound,
+			body:        `Not Found`,
+			expectedErr: "POST /: 404 Not Found: Not Found",
+			expectedIs:  "IsNotFound",
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(fixture.statusCode)
+				fmt.Fprint(w, fixture.body)
+			}))
+			t.Cleanup(server.Close)
+
+			marketplace, err := NewMarketplaceClient(client.Config{
+				AuthToken:  "my-token",
+				MaxRetries: -1,
+				Endpoints:  map[string]string{marketplaceServiceName: server.URL},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = marketplace.GetWellnessOfferingModule(context.Background(), "my-module")
+			if !assert.Error(t, err) {
+				return
+			}
+			assert.EqualError(t, err, fixture.expectedErr)
+
+			var errs Errors
+			if fixture.expectedValue != nil && assert.True(t, errors.As(err, &errs)) {
+				assert.Equal(t, fixture.expectedValue, errs)
+			}
+
+			wrapped := fmt.Errorf("failed to get module: %w", err)
+			for name, is := range map[string]func(error) bool{
+				"IsNotFound":     IsNotFound,
+				"IsForbidden":    IsForbidden,
+				"IsBadUserInput": IsBadUserInput,
+			} {
+				assert.Equal(t, name == fixture.expectedIs, is(wrapped), name)
+			}
+		})
+	}
+}
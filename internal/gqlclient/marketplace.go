@@ -9,8 +9,9 @@ import (
 )
 
 const (
-	marketplaceServiceName     = "marketplace-service"
-	marketplaceDefaultEndpoint = "https://marketplace.us.lifeomic.com/v1/marketplace/authenticated/graphql"
+	marketplaceServiceName = "marketplace-service"
+	marketplaceSubdomain   = "marketplace"
+	marketplacePath        = "/v1/marketplace/authenticated/graphql"
 )
 
 type MarketplaceService interface {
@@ -90,7 +91,15 @@ func (m *marketplaceClient) GetDraftWellnessOfferingModule(ctx context.Context,
 	return GetDraftWellnessOfferingModule(ctx, m.client, moduleId)
 }
 
-func NewMarketplaceClient(authToken string, accountID string, header map[string]string) MarketplaceService {
-	transport := client.NewAuthedTransport(authToken, accountID, marketplaceServiceName, header)
-	return &marketplaceClient{client: graphql.NewClient(marketplaceDefaultEndpoint, transport)}
+func NewMarketplaceClient(config client.Config) (MarketplaceService, error) {
+	config.ServiceName = marketplaceServiceName
+	transport, err := client.NewAuthedTransport(config)
+	if err != nil {
+		return nil, err
+	}
+	endpoint, err := config.ServiceURL(marketplaceSubdomain, marketplacePath)
+	if err != nil {
+		return nil, err
+	}
+	return &marketplaceClient{client: newGraphQLClient(endpoint, transport)}, nil
 }
@@ -0,0 +1,40 @@
+package gqlclient
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/client"
+)
+
+// ErrNoEndCursor is returned when a connection has a next page but no cursor
+// to fetch it with.
+var ErrNoEndCursor = errors.New("the API returned a next page without an end cursor")
+
+// paginate fetches every page of a Relay connection, which the generated
+// All<Operation> methods use. page is called with the cursor of each page,
+// starting with an empty cursor, and returns the page's pageInfo.endCursor
+// and pageInfo.hasNextPage. Like client.Iterator, it stops with
+// client.ErrRepeatedPageToken if the API returns a cursor more than once.
+func paginate(ctx context.Context, page func(after string) (endCursor string, hasNextPage bool, err error)) error {
+	seen := map[string]bool{}
+	var after string
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		endCursor, hasNextPage, err := page(after)
+		if err != nil || !hasNextPage {
+			return err
+		}
+		if endCursor == "" {
+			return ErrNoEndCursor
+		}
+		if seen[endCursor] {
+			return client.ErrRepeatedPageToken
+		}
+		seen[endCursor] = true
+		after = endCursor
+	}
+}
@@ -0,0 +1,88 @@
+package gqlclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/client"
+	"github.com/stretchr/testify/assert"
+)
+
+// testPages returns a page function serving the nodes named by pages, keyed
+// by their cursor, which records the cursors it's called with. The first
+// page has no cursor.
+func testPages(pages map[string][]string, endCursors map[string]string, nodes, cursors *[]string) func(string) (string, bool, error) {
+	return func(after string) (string, bool, error) {
+		*cursors = append(*cursors, after)
+		*nodes = append(*nodes, pages[after]...)
+		endCursor, hasNextPage := endCursors[after]
+		return endCursor, hasNextPage, nil
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	pages := map[string][]string{"": {"a", "b"}, "page2": {}, "page3": {"c"}}
+
+	for _, fixture := range []struct {
+		name            string
+		endCursors      map[string]string
+		expectedValue   []string
+		expectedCursors []string
+		expectedErr     error
+	}{
+		{
+			name:            "should follow the end cursor of every page",
+			endCursors:      map[string]string{"": "page2", "page2": "page3"},
+			expectedValue:   []string{"a", "b", "c"},
+			expectedCursors: []string{"", "page2", "page3"},
+		},
+		{
+			name:            "should stop when a cursor repeats",
+			endCursors:      map[string]string{"": "page2", "page2": "page2"},
+			expectedValue:   []string{"a", "b"},
+			expectedCursors: []string{"", "page2"},
+			expectedErr:     client.ErrRepeatedPageToken,
+		},
+		{
+			name:            "should require an end cursor for the next page",
+			endCursors:      map[string]string{"": ""},
+			expectedValue:   []string{"a", "b"},
+			expectedCursors: []string{""},
+			expectedErr:     ErrNoEndCursor,
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			var nodes, cursors []string
+			err := paginate(context.Background(), testPages(pages, fixture.endCursors, &nodes, &cursors))
+			if fixture.expectedErr != nil {
+				assert.ErrorIs(t, err, fixture.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, fixture.expectedValue, nodes)
+			assert.Equal(t, fixture.expectedCursors, cursors)
+		})
+	}
+}
+
+func TestPaginate_error(t *testing.T) {
+	pageErr := errors.New("page failed")
+	calls := 0
+	err := paginate(context.Background(), func(after string) (string, bool, error) {
+		calls++
+		return "page2", true, pageErr
+	})
+	assert.ErrorIs(t, err, pageErr)
+	assert.Equal(t, 1, calls, "should not fetch pages after an error")
+}
+
+func TestPaginate_context(t *testing.T) {
+	var nodes, cursors []string
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := paginate(ctx, testPages(map[string][]string{"": {"a"}}, nil, &nodes, &cursors))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, cursors, "should not fetch pages after the context is done")
+}
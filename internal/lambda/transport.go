@@ -3,23 +3,36 @@ package lambda
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"mime"
 	"net/http"
 	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/hashicorp/go-uuid"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
-const Proto = "lambda://"
-
-type URI struct {
-	Function  string
-	Qualifier string
-}
+var (
+	// ErrMissingRegion is returned when the AWS configuration doesn't
+	// specify a region to invoke lambda functions in.
+	ErrMissingRegion = errors.New("no AWS region configured, set $AWS_REGION or a region in the AWS config file")
+	// ErrMissingCredentials is returned when no AWS credentials could be
+	// found to invoke lambda functions with.
+	ErrMissingCredentials = errors.New("no valid AWS credentials found")
+)
 
 type Client interface {
 	Invoke(context.Context, *lambda.InvokeInput, ...func(*lambda.Options)) (*lambda.InvokeOutput, error)
@@ -32,25 +45,70 @@ type RoundTripper struct {
 	uri    *URI
 	client Client
 	header map[string]string
+	region string
+	// credentials are checked before each invocation, if set, so that
+	// missing credentials are reported as ErrMissingCredentials.
+	credentials aws.CredentialsProvider
+}
+
+// request represents an API Gateway proxy event, which is how the underlying
+// service's lambda expects to receive http requests.
+// See: https://docs.aws.amazon.com/apigateway/latest/developerguide/set-up-lambda-proxy-integrations.html#api-gateway-simple-proxy-for-lambda-input-format
+type request struct {
+	HTTPMethod                      string              `json:"httpMethod"`
+	Path                            string              `json:"path"`
+	Headers                         map[string]string   `json:"headers"`
+	MultiValueHeaders               map[string][]string `json:"multiValueHeaders"`
+	QueryStringParameters           map[string]string   `json:"queryStringParameters"`
+	MultiValueQueryStringParameters map[string][]string `json:"multiValueQueryStringParameters"`
+	RequestContext                  requestContext      `json:"requestContext"`
+	Body                            *string             `json:"body"`
+	IsBase64Encoded                 bool                `json:"isBase64Encoded"`
+}
+
+// requestContext represents the requestContext of an API Gateway proxy
+// event.
+type requestContext struct {
+	HTTPMethod       string `json:"httpMethod"`
+	Path             string `json:"path"`
+	RequestID        string `json:"requestId"`
+	RequestTimeEpoch int64  `json:"requestTimeEpoch"`
 }
 
 // payloadFromRequest converts the given http.Request into a payload to be
 // interpreted by the underlying service's lambda and marshals it to a byte
 // slice.
 func payloadFromRequest(req *http.Request, additionalHeader map[string]string) ([]byte, error) {
-	header := make(map[string]string, len(req.Header))
-	for key := range req.Header {
-		header[key] = req.Header.Get(key)
+	multiValueHeader := req.Header.Clone()
+	if multiValueHeader == nil {
+		multiValueHeader = make(http.Header, len(additionalHeader))
 	}
-
 	for k, v := range additionalHeader {
-		header[k] = v
+		multiValueHeader.Set(k, v)
+	}
+
+	requestID, err := uuid.GenerateUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	payload := request{
+		HTTPMethod:        req.Method,
+		Path:              req.URL.Path,
+		Headers:           lastValues(multiValueHeader),
+		MultiValueHeaders: multiValueHeader,
+		RequestContext: requestContext{
+			HTTPMethod:       req.Method,
+			Path:             req.URL.Path,
+			RequestID:        requestID,
+			RequestTimeEpoch: time.Now().UnixMilli(),
+		},
 	}
 
-	payload := map[string]any{
-		"headers":    header,
-		"httpMethod": req.Method,
-		"path":       req.URL.Path,
+	// API Gateway sends null query parameters rather than empty objects.
+	if query := req.URL.Query(); len(query) != 0 {
+		payload.QueryStringParameters = lastValues(query)
+		payload.MultiValueQueryStringParameters = query
 	}
 
 	if req.Body != nil {
@@ -60,45 +118,182 @@ func payloadFromRequest(req *http.Request, additionalHeader map[string]string) (
 		}
 		defer req.Body.Close()
 
-		payload["body"] = buf.String()
+		// JSON strings can't carry arbitrary bytes, so binary bodies are
+		// base64 encoded like API Gateway does.
+		body := buf.String()
+		if !utf8.Valid(buf.Bytes()) || !isTextContentType(req.Header.Get("Content-Type")) {
+			body = base64.StdEncoding.EncodeToString(buf.Bytes())
+			payload.IsBase64Encoded = true
+		}
+		payload.Body = &body
 	}
 	return json.Marshal(payload)
 }
 
+// isTextContentType reports whether bodies of contentType are text. Bodies
+// without a content type are assumed to be text.
+func isTextContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/x-www-form-urlencoded",
+		"application/graphql", "application/javascript":
+		return true
+	}
+	return false
+}
+
+// lastValues flattens a multi-value map by taking the last value for each key,
+// matching how API Gateway populates single value headers and query
+// parameters.
+func lastValues(m map[string][]string) map[string]string {
+	result := make(map[string]string, len(m))
+	for key, values := range m {
+		if len(values) != 0 {
+			result[key] = values[len(values)-1]
+		}
+	}
+	return result
+}
+
+// FunctionError is returned when the underlying service's lambda fails to
+// handle a request, e.g. because of an unhandled exception.
+type FunctionError struct {
+	// Function is the name of the invoked lambda function.
+	Function string `json:"-"`
+	// Kind is the lambda.InvokeOutput.FunctionError, either "Handled" or
+	// "Unhandled".
+	Kind string `json:"-"`
+
+	ErrorType    string   `json:"errorType"`
+	ErrorMessage string   `json:"errorMessage"`
+	StackTrace   []string `json:"-"`
+}
+
+func (e *FunctionError) Error() string {
+	message := fmt.Sprintf("lambda function %q failed (%s)", e.Function, e.Kind)
+	if e.ErrorType != "" {
+		message += ": " + e.ErrorType
+	}
+	if e.ErrorMessage != "" {
+		message += ": " + e.ErrorMessage
+	}
+	if len(e.StackTrace) != 0 {
+		message += "\n" + strings.Join(e.StackTrace, "\n")
+	}
+	return message
+}
+
+// functionErrorFromOutput creates a FunctionError from the error payload of a
+// failed lambda invocation.
+func functionErrorFromOutput(function string, output *lambda.InvokeOutput) error {
+	functionErr := &FunctionError{Function: function, Kind: *output.FunctionError}
+
+	var errPayload struct {
+		*FunctionError
+		StackTrace []any `json:"stackTrace"`
+	}
+	errPayload.FunctionError = functionErr
+	if err := json.Unmarshal(output.Payload, &errPayload); err != nil {
+		// The payload isn't the standard error shape, surface it as-is.
+		functionErr.ErrorMessage = string(output.Payload)
+		return functionErr
+	}
+
+	// Node runtimes report the stack trace as a list of strings, other
+	// runtimes as a list of frames.
+	for _, frame := range errPayload.StackTrace {
+		if line, ok := frame.(string); ok {
+			functionErr.StackTrace = append(functionErr.StackTrace, strings.TrimSpace(line))
+			continue
+		}
+		functionErr.StackTrace = append(functionErr.StackTrace, fmt.Sprint(frame))
+	}
+	return functionErr
+}
+
 // responseFromOutput creates an http.Response from a lambda.InvokeOutput to
 // satisfy the http transport.
 func responseFromOutput(output *lambda.InvokeOutput) (*http.Response, error) {
 	respPayload := new(response)
 	if err := json.Unmarshal(output.Payload, respPayload); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to parse lambda response: %w", err)
+	}
+
+	if respPayload.StatusCode == 0 {
+		return nil, fmt.Errorf("lambda response has no status code: %s", output.Payload)
 	}
 
-	header := make(http.Header, len(respPayload.Headers))
+	header := make(http.Header, len(respPayload.Headers)+len(respPayload.MultiValueHeaders))
 	for key, value := range respPayload.Headers {
 		header.Set(key, value)
 	}
+	// Multi-value headers take precedence, as API Gateway merges both.
+	for key, values := range respPayload.MultiValueHeaders {
+		header.Del(key)
+		for _, value := range values {
+			header.Add(key, value)
+		}
+	}
+
+	body := []byte(respPayload.Body)
+	if respPayload.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(respPayload.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode base64 lambda response body: %w", err)
+		}
+		body = decoded
+	}
 
 	return &http.Response{
-		Body:       ioutil.NopCloser(strings.NewReader(respPayload.Body)),
-		Header:     header,
-		StatusCode: respPayload.StatusCode,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Header:        header,
+		StatusCode:    respPayload.StatusCode,
+		Status:        fmt.Sprintf("%d %s", respPayload.StatusCode, http.StatusText(respPayload.StatusCode)),
 	}, nil
 }
 
 type response struct {
-	Body       string
-	StatusCode int
-	Headers    map[string]string
+	Body              string
+	StatusCode        int
+	Headers           map[string]string
+	MultiValueHeaders map[string][]string
+	IsBase64Encoded   bool
 }
 
-func (r *RoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+func (r *RoundTripper) RoundTrip(req *http.Request) (res *http.Response, err error) {
+	ctx, span := r.startSpan(req.Context())
+	defer func() { tracing.EndSpan(span, err) }()
+
+	// Propagate the invocation's span to the function, rather than the span
+	// of the HTTP request it replaces.
+	req = req.Clone(ctx)
+	tracing.Inject(ctx, req.Header)
+
+	if err := r.checkCredentials(ctx); err != nil {
+		return nil, err
+	}
+
 	payload, err := payloadFromRequest(req, r.header)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal lambda payload: %w", err)
 	}
 
-	output, err := r.client.Invoke(req.Context(), &lambda.InvokeInput{
+	output, err := r.client.Invoke(ctx, &lambda.InvokeInput{
 		FunctionName: &r.uri.Function,
 		Qualifier:    &r.uri.Qualifier,
 		Payload:      payload,
@@ -107,27 +302,85 @@ func (r *RoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 		return nil, err
 	}
 
+	if output.FunctionError != nil {
+		return nil, functionErrorFromOutput(r.uri.Function, output)
+	}
+
 	return responseFromOutput(output)
 }
 
+// checkCredentials returns an error wrapping ErrMissingCredentials if the
+// AWS credentials can't be retrieved. Credentials are retrieved when the
+// first function is invoked rather than when the RoundTripper is created, so
+// that configuring the provider doesn't call STS or IMDS unless lambda
+// functions are actually invoked.
+func (r *RoundTripper) checkCredentials(ctx context.Context) error {
+	if r.credentials == nil {
+		return nil
+	}
+	if _, err := r.credentials.Retrieve(ctx); err != nil {
+		return fmt.Errorf("%w: %s", ErrMissingCredentials, err)
+	}
+	return nil
+}
+
+// startSpan starts the client span of a function invocation.
+func (r *RoundTripper) startSpan(ctx context.Context) (context.Context, trace.Span) {
+	attributes := []attribute.KeyValue{
+		semconv.RPCSystemKey.String("aws-api"),
+		semconv.RPCServiceKey.String("Lambda"),
+		semconv.RPCMethodKey.String("Invoke"),
+		semconv.FaaSInvokedProviderAWS,
+		semconv.FaaSInvokedNameKey.String(r.uri.Function),
+	}
+	if r.region != "" {
+		attributes = append(attributes, semconv.FaaSInvokedRegionKey.String(r.region))
+	}
+	return tracing.StartSpan(ctx, "Lambda.Invoke",
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attributes...))
+}
+
 func (r *RoundTripper) Do(req *http.Request) (*http.Response, error) {
 	return r.RoundTrip(req)
 }
 
+// NewRoundTripper creates a RoundTripper which invokes the given function
+// using the default AWS configuration, i.e. the AWS_* environment variables
+// and shared config files.
+//
+// ErrMissingRegion is returned if the configuration doesn't resolve a region.
+// Credentials are only retrieved when a function is invoked, which fails with
+// an error wrapping ErrMissingCredentials if there are none.
 func NewRoundTripper(ctx context.Context, uri URI, header map[string]string) (*RoundTripper, error) {
 	cfg, err := config.LoadDefaultConfig(ctx)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	return newRoundTripperFromConfig(cfg, uri, header)
+}
+
+func newRoundTripperFromConfig(cfg aws.Config, uri URI, header map[string]string) (*RoundTripper, error) {
+	if cfg.Region == "" {
+		return nil, ErrMissingRegion
+	}
+	credentials := cfg.Credentials
+	if credentials == nil {
+		credentials = aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{}, errors.New("no credentials provider configured")
+		})
 	}
 
 	if uri.Qualifier == "" {
-		uri.Qualifier = "deployed"
+		uri.Qualifier = DefaultQualifier
 	}
 
 	lambdaClient := lambda.NewFromConfig(cfg)
 	return &RoundTripper{
-		client: lambdaClient,
-		uri:    &uri,
-		header: header,
+		client:      lambdaClient,
+		uri:         &uri,
+		header:      header,
+		region:      cfg.Region,
+		credentials: credentials,
 	}, nil
 }
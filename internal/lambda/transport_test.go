@@ -0,0 +1,400 @@
+package lambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClient implements Client, recording the last invocation and responding
+// with a fixed payload.
+type fakeClient struct {
+	input         *lambda.InvokeInput
+	payload       string
+	functionError *string
+}
+
+func (c *fakeClient) Invoke(_ context.Context, input *lambda.InvokeInput, _ ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+	c.input = input
+	return &lambda.InvokeOutput{StatusCode: 200, Payload: []byte(c.payload), FunctionError: c.functionError}, nil
+}
+
+func TestRoundTripper_RoundTrip_payload(t *testing.T) {
+	for _, fixture := range []struct {
+		name             string
+		method           string
+		url              string
+		header           http.Header
+		body             string
+		additionalHeader map[string]string
+		expectedPayload  request
+	}{
+		{
+			name:   "should send method, path, and headers",
+			method: http.MethodDelete,
+			url:    "https://api.us.lifeomic.com/v1/policies/my-policy",
+			header: http.Header{"Authorization": {"Bearer token"}},
+			expectedPayload: request{
+				HTTPMethod:        http.MethodDelete,
+				Path:              "/v1/policies/my-policy",
+				Headers:           map[string]string{"Authorization": "Bearer token"},
+				MultiValueHeaders: map[string][]string{"Authorization": {"Bearer token"}},
+			},
+		},
+		{
+			name:   "should send query string parameters",
+			method: http.MethodGet,
+			url:    "https://api.us.lifeomic.com/v1/policies?nextPageToken=abc&pageSize=10&tag=a&tag=b",
+			expectedPayload: request{
+				HTTPMethod:        http.MethodGet,
+				Path:              "/v1/policies",
+				Headers:           map[string]string{},
+				MultiValueHeaders: map[string][]string{},
+				QueryStringParameters: map[string]string{
+					"nextPageToken": "abc",
+					"pageSize":      "10",
+					"tag":           "b",
+				},
+				MultiValueQueryStringParameters: map[string][]string{
+					"nextPageToken": {"abc"},
+					"pageSize":      {"10"},
+					"tag":           {"a", "b"},
+				},
+			},
+		},
+		{
+			name:             "should send multi-value headers and additional headers",
+			method:           http.MethodPost,
+			url:              "https://api.us.lifeomic.com/v1/policies",
+			header:           http.Header{"Accept": {"application/json", "text/plain"}, "Lifeomic-Account": {"other"}},
+			body:             `{"name":"my-policy"}`,
+			additionalHeader: map[string]string{"LifeOmic-Account": "lifeomic"},
+			expectedPayload: request{
+				HTTPMethod: http.MethodPost,
+				Path:       "/v1/policies",
+				Headers: map[string]string{
+					"Accept":           "text/plain",
+					"Lifeomic-Account": "lifeomic",
+				},
+				MultiValueHeaders: map[string][]string{
+					"Accept":           {"application/json", "text/plain"},
+					"Lifeomic-Account": {"lifeomic"},
+				},
+				Body: stringPtr(`{"name":"my-policy"}`),
+			},
+		},
+		{
+			name:   "should base64 encode binary bodies",
+			method: http.MethodPut,
+			url:    "https://api.us.lifeomic.com/v1/files/my-file",
+			header: http.Header{"Content-Type": {"application/octet-stream"}},
+			body:   "plain bytes",
+			expectedPayload: request{
+				HTTPMethod:        http.MethodPut,
+				Path:              "/v1/files/my-file",
+				Headers:           map[string]string{"Content-Type": "application/octet-stream"},
+				MultiValueHeaders: map[string][]string{"Content-Type": {"application/octet-stream"}},
+				Body:              stringPtr("cGxhaW4gYnl0ZXM="),
+				IsBase64Encoded:   true,
+			},
+		},
+		{
+			name:   "should base64 encode bodies which aren't valid UTF-8",
+			method: http.MethodPost,
+			url:    "https://api.us.lifeomic.com/v1/files",
+			body:   "\xff\xfe\x00",
+			expectedPayload: request{
+				HTTPMethod:        http.MethodPost,
+				Path:              "/v1/files",
+				Headers:           map[string]string{},
+				MultiValueHeaders: map[string][]string{},
+				Body:              stringPtr("//4A"),
+				IsBase64Encoded:   true,
+			},
+		},
+		{
+			name:   "should send text bodies as-is",
+			method: http.MethodPost,
+			url:    "https://api.us.lifeomic.com/v1/notes",
+			header: http.Header{"Content-Type": {"text/plain; charset=utf-8"}},
+			body:   "héllo",
+			expectedPayload: request{
+				HTTPMethod:        http.MethodPost,
+				Path:              "/v1/notes",
+				Headers:           map[string]string{"Content-Type": "text/plain; charset=utf-8"},
+				MultiValueHeaders: map[string][]string{"Content-Type": {"text/plain; charset=utf-8"}},
+				Body:              stringPtr("héllo"),
+			},
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			client := &fakeClient{payload: `{"statusCode": 200, "body": "{}"}`}
+			roundTripper := &RoundTripper{
+				client: client,
+				uri:    &URI{Function: "account-service", Qualifier: "deployed"},
+				header: fixture.additionalHeader,
+			}
+
+			var body io.Reader
+			if fixture.body != "" {
+				body = strings.NewReader(fixture.body)
+			}
+			req, err := http.NewRequest(fixture.method, fixture.url, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for key, values := range fixture.header {
+				req.Header[key] = values
+			}
+
+			if _, err := roundTripper.RoundTrip(req); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			assert.Equal(t, "account-service", *client.input.FunctionName)
+			assert.Equal(t, "deployed", *client.input.Qualifier)
+
+			var payload request
+			if err := json.Unmarshal(client.input.Payload, &payload); err != nil {
+				t.Fatal(err)
+			}
+
+			assert.NotEmpty(t, payload.RequestContext.RequestID)
+			assert.NotZero(t, payload.RequestContext.RequestTimeEpoch)
+			assert.Equal(t, fixture.expectedPayload.HTTPMethod, payload.RequestContext.HTTPMethod)
+			assert.Equal(t, fixture.expectedPayload.Path, payload.RequestContext.Path)
+
+			payload.RequestContext = requestContext{}
+			assert.Equal(t, fixture.expectedPayload, payload)
+		})
+	}
+}
+
+func TestRoundTripper_RoundTrip_response(t *testing.T) {
+	for _, fixture := range []struct {
+		name           string
+		payload        string
+		functionError  *string
+		expectedStatus int
+		expectedHeader http.Header
+		expectedBody   string
+		expectedErr    string
+	}{
+		{
+			name:           "should convert response",
+			payload:        `{"statusCode": 404, "headers": {"Content-Type": "application/json"}, "body": "{\"error\": \"not found\"}"}`,
+			expectedStatus: http.StatusNotFound,
+			expectedHeader: http.Header{"Content-Type": {"application/json"}},
+			expectedBody:   `{"error": "not found"}`,
+		},
+		{
+			name:           "should decode base64 bodies",
+			payload:        `{"statusCode": 200, "body": "aGVsbG8gd29ybGQ=", "isBase64Encoded": true}`,
+			expectedStatus: http.StatusOK,
+			expectedHeader: http.Header{},
+			expectedBody:   "hello world",
+		},
+		{
+			name:           "should prefer multi-value headers",
+			payload:        `{"statusCode": 200, "headers": {"Set-Cookie": "b=2", "X-Request-Id": "abc"}, "multiValueHeaders": {"Set-Cookie": ["a=1", "b=2"]}, "body": ""}`,
+			expectedStatus: http.StatusOK,
+			expectedHeader: http.Header{"Set-Cookie": {"a=1", "b=2"}, "X-Request-Id": {"abc"}},
+		},
+		{
+			name:          "should return node function errors",
+			functionError: stringPtr("Unhandled"),
+			payload:       `{"errorType": "TypeError", "errorMessage": "Cannot read property 'id' of undefined", "stackTrace": ["TypeError: Cannot read property 'id' of undefined", "    at handler (/var/task/index.js:1:1)"]}`,
+			expectedErr: `lambda function "account-service" failed (Unhandled): TypeError: Cannot read property 'id' of undefined` +
+				"\nTypeError: Cannot read property 'id' of undefined\nat handler (/var/task/index.js:1:1)",
+		},
+		{
+			name:          "should return python function errors",
+			functionError: stringPtr("Unhandled"),
+			payload:       `{"errorType": "KeyError", "errorMessage": "'id'", "stackTrace": [["/var/task/app.py", 3, "handler", "event['id']"]]}`,
+			expectedErr:   `lambda function "account-service" failed (Unhandled): KeyError: 'id'` + "\n[/var/task/app.py 3 handler event['id']]",
+		},
+		{
+			name:          "should return malformed function errors",
+			functionError: stringPtr("Unhandled"),
+			payload:       `Task timed out after 6.01 seconds`,
+			expectedErr:   `lambda function "account-service" failed (Unhandled): Task timed out after 6.01 seconds`,
+		},
+		{
+			name:        "should reject responses without a status code",
+			payload:     `{"message": "Internal server error"}`,
+			expectedErr: "lambda response has no status code",
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			roundTripper := &RoundTripper{
+				client: &fakeClient{payload: fixture.payload, functionError: fixture.functionError},
+				uri:    &URI{Function: "account-service", Qualifier: "deployed"},
+			}
+
+			req, err := http.NewRequest(http.MethodGet, "https://api.us.lifeomic.com/v1/accounts", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := roundTripper.RoundTrip(req)
+			if err != nil {
+				if fixture.expectedErr == "" {
+					t.Errorf("unexpected error: %s", err)
+					return
+				}
+				assert.ErrorContains(t, err, fixture.expectedErr)
+				return
+			} else if fixture.expectedErr != "" {
+				t.Errorf("expected error matching: %s; got nil", fixture.expectedErr)
+				return
+			}
+
+			body, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, fixture.expectedStatus, res.StatusCode)
+			assert.Equal(t, fixture.expectedHeader, res.Header)
+			assert.Equal(t, fixture.expectedBody, string(body))
+		})
+	}
+}
+
+func TestFunctionError_As(t *testing.T) {
+	roundTripper := &RoundTripper{
+		client: &fakeClient{payload: `{"errorType": "Error", "errorMessage": "boom"}`, functionError: stringPtr("Handled")},
+		uri:    &URI{Function: "account-service"},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "https://api.us.lifeomic.com/v1/accounts", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = roundTripper.RoundTrip(req)
+
+	var functionErr *FunctionError
+	if assert.ErrorAs(t, err, &functionErr) {
+		assert.Equal(t, "Handled", functionErr.Kind)
+		assert.Equal(t, "Error", functionErr.ErrorType)
+		assert.Equal(t, "boom", functionErr.ErrorMessage)
+	}
+}
+
+func TestIsTextContentType(t *testing.T) {
+	for contentType, expected := range map[string]bool{
+		"":                                  true,
+		"application/json":                  true,
+		"application/json; charset=utf-8":   true,
+		"application/fhir+json":             true,
+		"application/x-www-form-urlencoded": true,
+		"text/csv":                          true,
+		"application/octet-stream":          false,
+		"image/png":                         false,
+		"not a media type;;":                false,
+	} {
+		assert.Equal(t, expected, isTextContentType(contentType), contentType)
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestNewRoundTripper_config(t *testing.T) {
+	for _, fixture := range []struct {
+		name        string
+		config      aws.Config
+		expectedErr error
+	}{
+		{
+			name: "should create round tripper",
+			config: aws.Config{
+				Region:      "us-east-1",
+				Credentials: credentialsFunc(nil),
+			},
+		},
+		{
+			name:        "should require a region",
+			config:      aws.Config{Credentials: credentialsFunc(nil)},
+			expectedErr: ErrMissingRegion,
+		},
+		{
+			name:   "should not retrieve credentials",
+			config: aws.Config{Region: "us-east-1", Credentials: credentialsFunc(errors.New("should not be called"))},
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			roundTripper, err := newRoundTripperFromConfig(fixture.config, URI{Function: "account-service"}, nil)
+			if fixture.expectedErr != nil {
+				assert.ErrorIs(t, err, fixture.expectedErr)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, &URI{Function: "account-service", Qualifier: DefaultQualifier}, roundTripper.uri)
+			}
+		})
+	}
+}
+
+func TestRoundTripper_RoundTrip_credentials(t *testing.T) {
+	for _, fixture := range []struct {
+		name        string
+		credentials aws.CredentialsProvider
+		expectedErr error
+	}{
+		{
+			name:        "should invoke functions with valid credentials",
+			credentials: credentialsFunc(nil),
+		},
+		{
+			name:        "should require credentials",
+			expectedErr: ErrMissingCredentials,
+		},
+		{
+			name:        "should require valid credentials",
+			credentials: credentialsFunc(errors.New("failed to refresh cached credentials")),
+			expectedErr: ErrMissingCredentials,
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			roundTripper, err := newRoundTripperFromConfig(aws.Config{Region: "us-east-1", Credentials: fixture.credentials},
+				URI{Function: "account-service"}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			client := &fakeClient{payload: `{"statusCode": 200, "body": "{}"}`}
+			roundTripper.client = client
+
+			req, err := http.NewRequest(http.MethodGet, "https://api.us.lifeomic.com/v1/accounts", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = roundTripper.RoundTrip(req)
+			if fixture.expectedErr != nil {
+				assert.ErrorIs(t, err, fixture.expectedErr)
+				assert.Nil(t, client.input, "should not invoke the function")
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+// credentialsFunc returns an aws.CredentialsProvider which fails with err
+// if it's non-nil.
+func credentialsFunc(err error) aws.CredentialsProvider {
+	return aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+		return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}, err
+	})
+}
@@ -0,0 +1,74 @@
+package lambda
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	Proto = "lambda://"
+
+	// DefaultQualifier is the function version or alias invoked when a URI
+	// doesn't specify one.
+	DefaultQualifier = "deployed"
+)
+
+// URI identifies a lambda function and the version or alias of it to invoke.
+type URI struct {
+	Function  string
+	Qualifier string
+}
+
+// ParseURI parses a lambda function reference such as
+// "lambda://function:qualifier". The lambda:// prefix and the qualifier are
+// optional, the function may be given by name or ARN, and any path following
+// the function is ignored, e.g.
+//
+//	lambda://wellness-service:deployed/v1/private/life-league
+//
+// identifies the "deployed" alias of the wellness-service function.
+func ParseURI(s string) (URI, error) {
+	rest := strings.TrimPrefix(s, Proto)
+	if strings.Contains(rest, "://") {
+		return URI{}, fmt.Errorf("invalid lambda URI %q: expected a %s URI", s, Proto)
+	}
+	if i := strings.Index(rest, "/"); i != -1 {
+		rest = rest[:i]
+	}
+
+	var uri URI
+	if strings.HasPrefix(rest, "arn:") {
+		// arn:partition:lambda:region:account:function:name[:qualifier]
+		parts := strings.Split(rest, ":")
+		switch len(parts) {
+		case 7:
+			uri.Function = rest
+		case 8:
+			uri.Function = strings.Join(parts[:7], ":")
+			uri.Qualifier = parts[7]
+		default:
+			return URI{}, fmt.Errorf("invalid lambda URI %q: malformed function ARN", s)
+		}
+	} else {
+		uri.Function = rest
+		if i := strings.Index(rest, ":"); i != -1 {
+			uri.Function, uri.Qualifier = rest[:i], rest[i+1:]
+			if uri.Qualifier == "" || strings.Contains(uri.Qualifier, ":") {
+				return URI{}, fmt.Errorf("invalid lambda URI %q: malformed qualifier", s)
+			}
+		}
+	}
+
+	if uri.Function == "" {
+		return URI{}, fmt.Errorf("invalid lambda URI %q: missing function name", s)
+	}
+	return uri, nil
+}
+
+// String returns the URI in lambda://function:qualifier form.
+func (u URI) String() string {
+	if u.Qualifier == "" {
+		return Proto + u.Function
+	}
+	return fmt.Sprintf("%s%s:%s", Proto, u.Function, u.Qualifier)
+}
@@ -0,0 +1,89 @@
+package lambda
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseURI(t *testing.T) {
+	for _, fixture := range []struct {
+		name          string
+		uri           string
+		expectedValue URI
+		expectedErr   string
+	}{
+		{
+			name:          "should parse function and qualifier",
+			uri:           "lambda://account-service:staging",
+			expectedValue: URI{Function: "account-service", Qualifier: "staging"},
+		},
+		{
+			name:          "should parse function without qualifier",
+			uri:           "lambda://account-service",
+			expectedValue: URI{Function: "account-service"},
+		},
+		{
+			name:          "should parse references without the lambda prefix",
+			uri:           "marketplace-service:42",
+			expectedValue: URI{Function: "marketplace-service", Qualifier: "42"},
+		},
+		{
+			name:          "should ignore paths",
+			uri:           "lambda://wellness-service:deployed/v1/private/life-league",
+			expectedValue: URI{Function: "wellness-service", Qualifier: "deployed"},
+		},
+		{
+			name:          "should parse function ARNs",
+			uri:           "lambda://arn:aws:lambda:us-east-1:123456789012:function:account-service",
+			expectedValue: URI{Function: "arn:aws:lambda:us-east-1:123456789012:function:account-service"},
+		},
+		{
+			name:          "should parse qualified function ARNs",
+			uri:           "arn:aws:lambda:us-east-1:123456789012:function:account-service:deployed",
+			expectedValue: URI{Function: "arn:aws:lambda:us-east-1:123456789012:function:account-service", Qualifier: "deployed"},
+		},
+		{
+			name:        "should reject other schemes",
+			uri:         "https://api.us.lifeomic.com",
+			expectedErr: "expected a lambda:// URI",
+		},
+		{
+			name:        "should reject missing function names",
+			uri:         "lambda://:deployed",
+			expectedErr: "missing function name",
+		},
+		{
+			name:        "should reject empty qualifiers",
+			uri:         "lambda://account-service:",
+			expectedErr: "malformed qualifier",
+		},
+		{
+			name:        "should reject malformed ARNs",
+			uri:         "arn:aws:lambda:us-east-1:function:account-service",
+			expectedErr: "malformed function ARN",
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			uri, err := ParseURI(fixture.uri)
+			if err != nil {
+				if fixture.expectedErr == "" {
+					t.Errorf("unexpected error: %s", err)
+					return
+				}
+				assert.ErrorContains(t, err, fixture.expectedErr)
+				return
+			} else if fixture.expectedErr != "" {
+				t.Errorf("expected error matching: %s; got nil", fixture.expectedErr)
+				return
+			}
+
+			assert.Equal(t, fixture.expectedValue, uri)
+		})
+	}
+}
+
+func TestURI_String(t *testing.T) {
+	assert.Equal(t, "lambda://account-service:deployed", URI{Function: "account-service", Qualifier: "deployed"}.String())
+	assert.Equal(t, "lambda://account-service", URI{Function: "account-service"}.String())
+}
@@ -0,0 +1,212 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/client"
+)
+
+// providerAuth represents the provider's auth block.
+type providerAuth struct {
+	TokenURL     types.String `tfsdk:"token_url"`
+	ClientID     types.String `tfsdk:"client_id"`
+	ClientSecret types.String `tfsdk:"client_secret"`
+	RefreshToken types.String `tfsdk:"refresh_token"`
+	Scopes       []string     `tfsdk:"scopes"`
+}
+
+func authBlock() tfsdk.Block {
+	return tfsdk.Block{
+		Description: "Obtain access tokens from an OAuth token endpoint instead of using a static `token`. " +
+			"Tokens are refreshed automatically before they expire, or when the PHC API rejects them.",
+		NestingMode: tfsdk.BlockNestingModeList,
+		MaxItems:    1,
+		Attributes: map[string]tfsdk.Attribute{
+			"token_url": {
+				Type:        types.StringType,
+				Required:    true,
+				Description: "The URL of the OAuth token endpoint.",
+			},
+			"client_id": {
+				Type:        types.StringType,
+				Required:    true,
+				Description: "The OAuth client ID.",
+			},
+			"client_secret": {
+				Type:      types.StringType,
+				Optional:  true,
+				Sensitive: true,
+				Description: "The OAuth client secret. If `refresh_token` isn't set, it's exchanged for access tokens " +
+					"using the client credentials grant.",
+			},
+			"refresh_token": {
+				Type:        types.StringType,
+				Optional:    true,
+				Sensitive:   true,
+				Description: "A refresh token to exchange for access tokens using the refresh token grant.",
+			},
+			"scopes": {
+				Type:        types.ListType{ElemType: types.StringType},
+				Optional:    true,
+				Description: "The scopes to request access tokens for.",
+			},
+		},
+	}
+}
+
+// newOAuthTokenSource creates a TokenSource from the provider's auth block,
+// requesting tokens with httpClient.
+func newOAuthTokenSource(auth providerAuth, httpClient *http.Client) (client.TokenSource, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if auth.ClientSecret.Null && auth.RefreshToken.Null {
+		diags.AddAttributeError(path.Root("auth").AtListIndex(0).AtName("client_secret"),
+			"Missing OAuth credentials",
+			"Either \"client_secret\" or \"refresh_token\" must be set in the auth block")
+		return nil, diags
+	}
+	if _, err := url.ParseRequestURI(auth.TokenURL.Value); err != nil {
+		diags.AddAttributeError(path.Root("auth").AtListIndex(0).AtName("token_url"), "Invalid OAuth token URL", err.Error())
+		return nil, diags
+	}
+
+	return client.NewCachingTokenSource(client.NewOAuthTokenSource(client.OAuthConfig{
+		TokenURL:     auth.TokenURL.Value,
+		ClientID:     auth.ClientID.Value,
+		ClientSecret: auth.ClientSecret.Value,
+		RefreshToken: auth.RefreshToken.Value,
+		Scopes:       auth.Scopes,
+	}, httpClient)), diags
+}
+
+// loadProviderProfile loads the profile selected by the provider's profile and
+// config_file attributes, or their environment variables. The default
+// profile is optional, so nil is returned if it doesn't exist.
+func loadProviderProfile(config *providerData) (*client.Profile, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	name := providerValue(config.Profile, client.ProfileEnvVar)
+	configFile := providerValue(config.ConfigFile, client.ConfigFileEnvVar)
+	explicit, explicitFile := name != "", configFile != ""
+	if name == "" {
+		name = client.DefaultProfile
+	}
+
+	var err error
+	if configFile == "" {
+		configFile, err = client.DefaultConfigFile()
+	} else {
+		configFile, err = client.ExpandPath(configFile)
+	}
+	if err != nil {
+		if explicit || explicitFile {
+			diags.AddAttributeError(path.Root("config_file"), "Unable to locate config file", err.Error())
+		}
+		return nil, diags
+	}
+
+	profile, err := client.LoadProfile(configFile, name)
+	if err != nil {
+		notFound := errors.Is(err, client.ErrProfileNotFound) || (!explicitFile && errors.Is(err, fs.ErrNotExist))
+		if !explicit && notFound {
+			return nil, diags
+		}
+		diags.AddAttributeError(path.Root("profile"), fmt.Sprintf("Unable to load profile %q", name), err.Error())
+		return nil, diags
+	}
+	return profile, diags
+}
+
+// resolveCredentials determines how the provider authenticates. Credentials
+// are taken from, in order of precedence, the auth block or the token,
+// token_file, and credential_process attributes, their environment variables,
+// and finally the profile. Either a static token or a TokenSource is
+// returned. OAuth tokens are requested with httpClient.
+func resolveCredentials(config *providerData, profile *client.Profile, httpClient *http.Client) (string, client.TokenSource, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	// Only one kind of credentials may be set in the provider block.
+	var explicit []string
+	if len(config.Auth) != 0 {
+		explicit = append(explicit, "auth")
+	}
+	for _, attribute := range []struct {
+		name  string
+		value types.String
+	}{
+		{"token", config.Token},
+		{"token_file", config.TokenFile},
+		{"credential_process", config.CredentialProcess},
+	} {
+		if attribute.value.Value != "" {
+			explicit = append(explicit, attribute.name)
+		}
+	}
+	if len(explicit) > 1 {
+		diags.AddAttributeError(path.Root(explicit[1]), "Conflicting provider values",
+			fmt.Sprintf("Only one of %q and %q may be set in the provider block", explicit[0], explicit[1]))
+		return "", nil, diags
+	}
+
+	switch {
+	case len(config.Auth) != 0:
+		tokenSource, diags := newOAuthTokenSource(config.Auth[0], httpClient)
+		return "", tokenSource, diags
+	case config.Token.Value != "":
+		return config.Token.Value, nil, diags
+	case config.TokenFile.Value != "":
+		return newFileTokenSource(path.Root("token_file"), config.TokenFile.Value)
+	case config.CredentialProcess.Value != "":
+		return "", client.NewCachingTokenSource(client.NewCommandTokenSource(config.CredentialProcess.Value)), diags
+	}
+
+	if token := os.Getenv(client.AuthTokenEnvVar); token != "" {
+		return token, nil, diags
+	}
+	if tokenFile := os.Getenv(client.TokenFileEnvVar); tokenFile != "" {
+		return newFileTokenSource(path.Root("token_file"), tokenFile)
+	}
+	if command := os.Getenv(client.CredentialProcessEnvVar); command != "" {
+		return "", client.NewCachingTokenSource(client.NewCommandTokenSource(command)), diags
+	}
+
+	if profile != nil {
+		if tokenSource := profile.TokenSource(); tokenSource != nil {
+			return "", tokenSource, diags
+		}
+	}
+
+	diags.AddAttributeError(path.Root("token"), `Missing required provider value "token"`,
+		fmt.Sprintf("Either set \"token\", \"token_file\", \"credential_process\", or the auth block in the provider block, "+
+			"set the %s, %s, or %s environment variable, or select a profile with credentials from the LifeOmic config file",
+			client.AuthTokenEnvVar, client.TokenFileEnvVar, client.CredentialProcessEnvVar))
+	return "", nil, diags
+}
+
+func newFileTokenSource(attributePath path.Path, tokenFile string) (string, client.TokenSource, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	tokenFile, err := client.ExpandPath(tokenFile)
+	if err != nil {
+		diags.AddAttributeError(attributePath, "Invalid token file", err.Error())
+		return "", nil, diags
+	}
+	return "", client.NewCachingTokenSource(client.NewFileTokenSource(tokenFile)), diags
+}
+
+// providerValue returns the value of an optional provider attribute, falling
+// back to the environment variable if it isn't set.
+func providerValue(value types.String, envVar string) string {
+	if value.Value != "" {
+		return value.Value
+	}
+	return os.Getenv(envVar)
+}
@@ -0,0 +1,198 @@
+package provider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewOAuthTokenSource(t *testing.T) {
+	_, diags := newOAuthTokenSource(providerAuth{
+		TokenURL:     types.String{Value: "https://example.com/oauth2/token"},
+		ClientID:     types.String{Value: "my-client"},
+		ClientSecret: types.String{Null: true},
+		RefreshToken: types.String{Null: true},
+	}, nil)
+	if assert.True(t, diags.HasError()) {
+		assert.Equal(t, "Missing OAuth credentials", diags[0].Summary())
+	}
+
+	source, diags := newOAuthTokenSource(providerAuth{
+		TokenURL:     types.String{Value: "https://example.com/oauth2/token"},
+		ClientID:     types.String{Value: "my-client"},
+		ClientSecret: types.String{Null: true},
+		RefreshToken: types.String{Value: "my-refresh-token"},
+	}, nil)
+	assert.False(t, diags.HasError())
+	assert.IsType(t, &client.CachingTokenSource{}, source)
+	_, diags = newOAuthTokenSource(providerAuth{
+		TokenURL:     types.String{Value: ""},
+		ClientID:     types.String{Value: "my-client"},
+		ClientSecret: types.String{Value: "my-secret"},
+		RefreshToken: types.String{Null: true},
+	}, nil)
+	if assert.True(t, diags.HasError()) {
+		assert.Equal(t, "Invalid OAuth token URL", diags[0].Summary())
+	}
+}
+
+func TestLoadProviderProfile(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configFile, []byte("profiles:\n  default:\n    account: my-account\n  eu:\n    region: eu\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "empty.yaml"), []byte("profiles: {}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, fixture := range []struct {
+		name          string
+		profile       string
+		configFile    string
+		expectedValue *client.Profile
+		expectedErr   string
+	}{
+		{
+			name:          "should load the default profile",
+			configFile:    configFile,
+			expectedValue: &client.Profile{Account: "my-account"},
+		},
+		{
+			name:          "should load named profiles",
+			profile:       "eu",
+			configFile:    configFile,
+			expectedValue: &client.Profile{Region: "eu"},
+		},
+		{
+			name:       "should ignore a missing default profile",
+			configFile: filepath.Join(dir, "empty.yaml"),
+		},
+		{
+			name:        "should reject missing named profiles",
+			profile:     "staging",
+			configFile:  configFile,
+			expectedErr: `Unable to load profile "staging"`,
+		},
+		{
+			name:        "should reject missing config files",
+			profile:     "eu",
+			configFile:  filepath.Join(dir, "missing.yaml"),
+			expectedErr: `Unable to load profile "eu"`,
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			t.Setenv(client.ProfileEnvVar, "")
+			t.Setenv(client.ConfigFileEnvVar, "")
+
+			profile, diags := loadProviderProfile(&providerData{
+				Profile:    types.String{Value: fixture.profile, Null: fixture.profile == ""},
+				ConfigFile: types.String{Value: fixture.configFile},
+			})
+			if diags.HasError() {
+				if fixture.expectedErr == "" {
+					t.Errorf("unexpected error: %v", diags)
+					return
+				}
+				assert.Equal(t, fixture.expectedErr, diags[0].Summary())
+				return
+			} else if fixture.expectedErr != "" {
+				t.Errorf("expected error matching: %s; got nil", fixture.expectedErr)
+				return
+			}
+
+			assert.Equal(t, fixture.expectedValue, profile)
+		})
+	}
+}
+
+func TestResolveCredentials(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("my-file-token"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, fixture := range []struct {
+		name          string
+		config        providerData
+		env           map[string]string
+		profile       *client.Profile
+		expectedValue string
+		expectedErr   string
+	}{
+		{
+			name:          "should use the token",
+			config:        providerData{Token: types.String{Value: "my-token"}},
+			env:           map[string]string{client.CredentialProcessEnvVar: "echo my-env-token"},
+			expectedValue: "my-token",
+		},
+		{
+			name:          "should prefer the token file to environment variables",
+			config:        providerData{TokenFile: types.String{Value: tokenFile}},
+			env:           map[string]string{client.AuthTokenEnvVar: "my-env-token"},
+			expectedValue: "my-file-token",
+		},
+		{
+			name:          "should run the credential process",
+			config:        providerData{CredentialProcess: types.String{Value: "echo my-process-token"}},
+			expectedValue: "my-process-token",
+		},
+		{
+			name:          "should prefer environment variables to the profile",
+			env:           map[string]string{client.TokenFileEnvVar: tokenFile},
+			profile:       &client.Profile{Token: "my-profile-token"},
+			expectedValue: "my-file-token",
+		},
+		{
+			name:          "should use the profile",
+			profile:       &client.Profile{Token: "my-profile-token"},
+			expectedValue: "my-profile-token",
+		},
+		{
+			name: "should reject conflicting credentials",
+			config: providerData{
+				Token:     types.String{Value: "my-token"},
+				TokenFile: types.String{Value: tokenFile},
+			},
+			expectedErr: "Conflicting provider values",
+		},
+		{
+			name:        "should require credentials",
+			profile:     &client.Profile{Account: "my-account"},
+			expectedErr: `Missing required provider value "token"`,
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			for _, envVar := range []string{client.AuthTokenEnvVar, client.TokenFileEnvVar, client.CredentialProcessEnvVar} {
+				t.Setenv(envVar, fixture.env[envVar])
+			}
+
+			authToken, tokenSource, diags := resolveCredentials(&fixture.config, fixture.profile, nil)
+			if diags.HasError() {
+				if fixture.expectedErr == "" {
+					t.Errorf("unexpected error: %v", diags)
+					return
+				}
+				assert.Equal(t, fixture.expectedErr, diags[0].Summary())
+				return
+			} else if fixture.expectedErr != "" {
+				t.Errorf("expected error matching: %s; got nil", fixture.expectedErr)
+				return
+			}
+
+			if tokenSource != nil {
+				token, err := tokenSource.Token(context.Background())
+				if err != nil {
+					t.Fatal(err)
+				}
+				authToken = token.AccessToken
+			}
+			assert.Equal(t, fixture.expectedValue, authToken)
+		})
+	}
+}
@@ -1,28 +1,133 @@
 package provider
 
 import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/lifeomic/terraform-provider-lifeomic/internal/client"
 	"github.com/lifeomic/terraform-provider-lifeomic/internal/gqlclient"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/tracing"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type clientSet struct {
 	AppStore    gqlclient.AppStoreService
+	Accounts    client.AccountService
 	Policies    client.PolicyService
 	Marketplace gqlclient.MarketplaceService
+
+	// UseLambda is true if requests invoke the services' lambda functions
+	// directly.
+	UseLambda bool
+
+	// config is the configuration the clients were created with. It's used
+	// to create the clients of other accounts.
+	config client.Config
+
+	mu       sync.Mutex
+	accounts map[string]*clientSet
 }
 
-func newClientSet(token, accountID string, headers map[string]string) *clientSet {
-	policiesClient := client.New(client.Config{
-		AuthToken:   token,
-		AccountID:   accountID,
-		ServiceName: "account-service",
-		Header:      headers,
-	}).Policies()
+func newClientSet(config client.Config) (*clientSet, error) {
+	// The clients of other accounts share the transports of these ones, see
+	// ForAccount.
+	if config.BaseTransports == nil {
+		config.BaseTransports = new(client.BaseTransports)
+	}
+
+	accountConfig := config
+	accountConfig.ServiceName = "account-service"
+	accountClient, err := client.New(accountConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	appStoreClient, err := gqlclient.NewAppStoreClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	marketplaceClient, err := gqlclient.NewMarketplaceClient(config)
+	if err != nil {
+		return nil, err
+	}
 
 	return &clientSet{
-		AppStore:    gqlclient.NewAppStoreClient(token, accountID, headers),
-		Marketplace: gqlclient.NewMarketplaceClient(token, accountID, headers),
+		AppStore:    appStoreClient,
+		Marketplace: marketplaceClient,
+
+		Accounts: accountClient.Accounts(),
+		Policies: accountClient.Policies(),
+
+		UseLambda: config.LambdaEnabled(),
+
+		config: config,
+	}, nil
+}
+
+// ForAccount returns a clientSet sending requests to the given account. The
+// clientSet is created on first use and cached, so that resources in the same
+// account share their clients. Its clients only differ from c's in their
+// account, sharing c's lambda and HTTP transports, rate limiters, and token
+// source. c itself is returned if accountID is empty or c's account.
+func (c *clientSet) ForAccount(accountID string) (*clientSet, error) {
+	if accountID == "" || accountID == c.config.AccountID {
+		return c, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if accountClientSet, ok := c.accounts[accountID]; ok {
+		return accountClientSet, nil
+	}
+
+	config := c.config
+	config.AccountID = accountID
+	accountClientSet, err := newClientSet(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.accounts == nil {
+		c.accounts = map[string]*clientSet{}
+	}
+	c.accounts[accountID] = accountClientSet
+	return accountClientSet, nil
+}
+
+// forResource returns the clientSet for a resource's account_id attribute,
+// which defaults to the provider's account if it's null. The account is
+// recorded in the span of ctx's operation.
+func (c *clientSet) forResource(ctx context.Context, accountID types.String) (*clientSet, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	accountClientSet, err := c.ForAccount(accountID.Value)
+	if err != nil {
+		diags.AddAttributeError(path.Root("account_id"),
+			fmt.Sprintf("Unable to create PHC clients for account %q", accountID.Value), err.Error())
+		return nil, diags
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(tracing.AccountIDKey.String(accountClientSet.config.AccountID))
+	return accountClientSet, diags
+}
 
-		Policies: policiesClient,
+// accountIDAttribute returns the schema of the account_id attribute shared by
+// all resources.
+func accountIDAttribute() tfsdk.Attribute {
+	return tfsdk.Attribute{
+		Type:     types.StringType,
+		Optional: true,
+		Description: "The ID of the PHC account to manage this resource in. " +
+			"Defaults to the provider's `account_id`. Changing it forces a new resource to be created.",
+		PlanModifiers: tfsdk.AttributePlanModifiers{
+			tfsdk.RequiresReplace(),
+		},
 	}
 }
@@ -0,0 +1,39 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientSet_ForAccount(t *testing.T) {
+	t.Setenv(client.UseLambdaEnvVar, "")
+
+	clientSet, err := newClientSet(client.Config{AccountID: "my-account", AuthToken: "my-token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, accountID := range []string{"", "my-account"} {
+		accountClientSet, err := clientSet.ForAccount(accountID)
+		if assert.NoError(t, err) {
+			assert.Same(t, clientSet, accountClientSet, "should use the provider's clients for account %q", accountID)
+		}
+	}
+
+	other, err := clientSet.ForAccount("other-account")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotSame(t, clientSet, other)
+	assert.Equal(t, "other-account", other.config.AccountID)
+	assert.Equal(t, "my-token", other.config.AuthToken)
+	assert.Same(t, clientSet.config.BaseTransports, other.config.BaseTransports,
+		"should share the transports of the provider's clients")
+
+	cached, err := clientSet.ForAccount("other-account")
+	if assert.NoError(t, err) {
+		assert.Same(t, other, cached, "should cache the clients of other accounts")
+	}
+}
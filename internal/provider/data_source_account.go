@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// accountDataSource implements tfsdk.DataSource.
+type accountDataSource struct {
+	clientSet *clientSet
+}
+
+// accountDataSourceType implements tfsdk.DataSourceType.
+type accountDataSourceType struct{}
+
+func (accountDataSourceType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	attributes := accountAttributes()
+	attributes["id"] = tfsdk.Attribute{
+		Type:        types.StringType,
+		Required:    true,
+		Description: "The ID of the account.",
+	}
+
+	return tfsdk.Schema{
+		Description: fmt.Sprintf("`lifeomic_account` looks up a [PHC account](%s) by its ID. "+
+			"Reading the data source fails if the provider's credentials can't access the account.", accountsDocsURL),
+		Attributes: attributes,
+	}, nil
+}
+
+func (accountDataSourceType) NewDataSource(_ context.Context, p tfsdk.Provider) (tfsdk.DataSource, diag.Diagnostics) {
+	pr, ok := p.(*provider)
+	if !ok {
+		return nil, errorConvertingProvider(p)
+	}
+
+	return &accountDataSource{
+		clientSet: pr.clientSet,
+	}, nil
+}
+
+func (d accountDataSource) Read(ctx context.Context, req tfsdk.ReadDataSourceRequest, resp *tfsdk.ReadDataSourceResponse) {
+	tflog.Info(ctx, "Reading Account data source")
+	ctx, span := startOperation(ctx, "lifeomic_account", "read")
+	defer func() { endOperation(span, resp.Diagnostics) }()
+
+	var config account
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	accounts, err := d.clientSet.Accounts.List(ctx)
+	if err != nil {
+		resp.Diagnostics.Append(apiErrorDiagnostic("failed to list accounts", err))
+		return
+	}
+
+	for _, a := range accounts {
+		if a.ID == config.ID.Value {
+			tflog.Info(ctx, "Got Account", map[string]any{"account": a})
+			resp.Diagnostics.Append(resp.State.Set(ctx, newAccount(a))...)
+			return
+		}
+	}
+
+	resp.Diagnostics.AddAttributeError(path.Root("id"), "Account not found",
+		fmt.Sprintf("The account %q doesn't exist or the provider's credentials can't access it", config.ID.Value))
+}
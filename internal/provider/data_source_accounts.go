@@ -0,0 +1,149 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/client"
+)
+
+const accountsDocsURL = "https://docs.us.lifeomic.com/api/#lifeomic-core-api-accounts"
+
+// accountTypes are the possible values of an account's type.
+var accountTypes = []client.AccountType{
+	client.AccountTypeFree,
+	client.AccountTypePaid,
+	client.AccountTypeEnterprise,
+}
+
+// account represents a PHC account in the state of the lifeomic_account and
+// lifeomic_accounts data sources.
+type account struct {
+	ID    types.String `tfsdk:"id"`
+	Name  types.String `tfsdk:"name"`
+	Owner types.String `tfsdk:"owner"`
+	Type  types.String `tfsdk:"type"`
+}
+
+// newAccount converts a client.Account into its state.
+func newAccount(a client.Account) account {
+	return account{
+		ID:    types.String{Value: a.ID},
+		Name:  types.String{Value: a.Name},
+		Owner: types.String{Value: a.Owner},
+		Type:  types.String{Value: string(a.Type)},
+	}
+}
+
+// accountAttributes returns the computed attributes describing an account.
+func accountAttributes() map[string]tfsdk.Attribute {
+	typeNames := make([]string, len(accountTypes))
+	for i, accountType := range accountTypes {
+		typeNames[i] = fmt.Sprintf("`%s`", accountType)
+	}
+
+	return map[string]tfsdk.Attribute{
+		"name": {
+			Type:        types.StringType,
+			Computed:    true,
+			Description: "The display name of the account.",
+		},
+		"owner": {
+			Type:        types.StringType,
+			Computed:    true,
+			Description: "The ID of the user who owns the account.",
+		},
+		"type": {
+			Type:        types.StringType,
+			Computed:    true,
+			Description: fmt.Sprintf("The type of the account, one of %s.", strings.Join(typeNames, ", ")),
+		},
+	}
+}
+
+// accountList represents the state of a lifeomic_accounts data source.
+type accountList struct {
+	ID       types.String `tfsdk:"id"`
+	IDs      []string     `tfsdk:"ids"`
+	Accounts []account    `tfsdk:"accounts"`
+}
+
+// accountsDataSource implements tfsdk.DataSource.
+type accountsDataSource struct {
+	clientSet *clientSet
+}
+
+// accountsDataSourceType implements tfsdk.DataSourceType.
+type accountsDataSourceType struct{}
+
+func (accountsDataSourceType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	attributes := accountAttributes()
+	attributes["id"] = tfsdk.Attribute{
+		Type:        types.StringType,
+		Computed:    true,
+		Description: "The ID of the account.",
+	}
+
+	return tfsdk.Schema{
+		Description: fmt.Sprintf("`lifeomic_accounts` lists the [PHC accounts](%s) the provider's credentials can access.", accountsDocsURL),
+		Attributes: map[string]tfsdk.Attribute{
+			"id": {
+				Type:        types.StringType,
+				Computed:    true,
+				Description: "A hash of the listed account IDs.",
+			},
+			"ids": {
+				Type:        types.ListType{ElemType: types.StringType},
+				Computed:    true,
+				Description: "The IDs of the accounts, e.g. for checking an `account_id` with `contains()`.",
+			},
+			"accounts": {
+				Computed:    true,
+				Description: "The accounts.",
+				Attributes:  tfsdk.ListNestedAttributes(attributes),
+			},
+		},
+	}, nil
+}
+
+func (accountsDataSourceType) NewDataSource(_ context.Context, p tfsdk.Provider) (tfsdk.DataSource, diag.Diagnostics) {
+	pr, ok := p.(*provider)
+	if !ok {
+		return nil, errorConvertingProvider(p)
+	}
+
+	return &accountsDataSource{
+		clientSet: pr.clientSet,
+	}, nil
+}
+
+func (d accountsDataSource) Read(ctx context.Context, req tfsdk.ReadDataSourceRequest, resp *tfsdk.ReadDataSourceResponse) {
+	tflog.Info(ctx, "Reading Accounts data source")
+	ctx, span := startOperation(ctx, "lifeomic_accounts", "read")
+	defer func() { endOperation(span, resp.Diagnostics) }()
+
+	accounts, err := d.clientSet.Accounts.List(ctx)
+	if err != nil {
+		resp.Diagnostics.Append(apiErrorDiagnostic("failed to list accounts", err))
+		return
+	}
+
+	state := accountList{
+		IDs:      make([]string, len(accounts)),
+		Accounts: make([]account, len(accounts)),
+	}
+	for i, a := range accounts {
+		state.IDs[i] = a.ID
+		state.Accounts[i] = newAccount(a)
+	}
+	state.ID = types.String{Value: fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(state.IDs, ","))))}
+
+	tflog.Info(ctx, "Listed accounts", map[string]any{"accounts": state.IDs})
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+}
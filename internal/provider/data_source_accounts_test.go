@@ -0,0 +1,47 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/client"
+)
+
+func TestAccPHCAccounts_basic(t *testing.T) {
+	t.Parallel()
+	accountID := os.Getenv(client.AccountIDEnvVar)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 testAccPreCheck(t),
+		ProtoV6ProviderFactories: testAccProviderFactories,
+
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+data "lifeomic_accounts" "all" {}
+
+data "lifeomic_account" "current" {
+  id = %q
+}
+`, accountID),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckTypeSetElemAttr("data.lifeomic_accounts.all", "ids.*", accountID),
+					resource.TestCheckResourceAttr("data.lifeomic_account.current", "id", accountID),
+					resource.TestCheckResourceAttrSet("data.lifeomic_account.current", "name"),
+					resource.TestCheckResourceAttrSet("data.lifeomic_account.current", "type"),
+				),
+			},
+			{
+				Config: `
+data "lifeomic_account" "missing" {
+  id = "tf-test-missing-account"
+}
+`,
+				ExpectError: regexp.MustCompile("Account not found"),
+			},
+		},
+	})
+}
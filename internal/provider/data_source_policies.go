@@ -0,0 +1,205 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/client"
+)
+
+// policyListItem represents a policy in the state of a lifeomic_policies data
+// source.
+type policyListItem struct {
+	Name       types.String `tfsdk:"name"`
+	PolicyJSON types.String `tfsdk:"policy_json"`
+}
+
+// policyList represents the state of a lifeomic_policies data source.
+type policyList struct {
+	ID               types.String     `tfsdk:"id"`
+	AccountID        types.String     `tfsdk:"account_id"`
+	NamePrefix       types.String     `tfsdk:"name_prefix"`
+	NameRegex        types.String     `tfsdk:"name_regex"`
+	IncludeDocuments types.Bool       `tfsdk:"include_documents"`
+	Names            []string         `tfsdk:"names"`
+	Policies         []policyListItem `tfsdk:"policies"`
+}
+
+// policiesDataSource implements tfsdk.DataSource.
+type policiesDataSource struct {
+	clientSet *clientSet
+}
+
+// policiesDataSourceType implements tfsdk.DataSourceType.
+type policiesDataSourceType struct{}
+
+func (policiesDataSourceType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	return tfsdk.Schema{
+		Description: fmt.Sprintf("`lifeomic_policies` lists the [ABAC policies](%s) in a PHC account, "+
+			"e.g. to find policies which aren't managed by Terraform.", policyDocsURL),
+		Attributes: map[string]tfsdk.Attribute{
+			"id": {
+				Type:        types.StringType,
+				Computed:    true,
+				Description: "A hash of the listed policy names.",
+			},
+			"account_id": {
+				Type:     types.StringType,
+				Optional: true,
+				Description: "The ID of the PHC account to list policies in. " +
+					"Defaults to the provider's `account_id`.",
+			},
+			"name_prefix": {
+				Type:        types.StringType,
+				Optional:    true,
+				Description: "Only list policies whose names start with this prefix.",
+			},
+			"name_regex": {
+				Type:     types.StringType,
+				Optional: true,
+				Description: "Only list policies whose names match this [regular expression](https://github.com/google/re2/wiki/Syntax). " +
+					"The expression isn't anchored, so use `^` and `$` to match whole names.",
+				Validators: []tfsdk.AttributeValidator{
+					&regexValidator{},
+				},
+			},
+			"include_documents": {
+				Type:     types.BoolType,
+				Optional: true,
+				Description: "Whether to set the `policy_json` of each policy. " +
+					"Defaults to `false` to keep large policy documents out of the state.",
+			},
+			"names": {
+				Type:        types.ListType{ElemType: types.StringType},
+				Computed:    true,
+				Description: "The names of the policies, in the order the API returns them.",
+			},
+			"policies": {
+				Computed:    true,
+				Description: "The policies.",
+				Attributes: tfsdk.ListNestedAttributes(map[string]tfsdk.Attribute{
+					"name": {
+						Type:        types.StringType,
+						Computed:    true,
+						Description: "The unique name of the policy.",
+					},
+					"policy_json": {
+						Type:     types.StringType,
+						Computed: true,
+						Description: "The JSON encoded ABAC policy document, " +
+							"or null unless `include_documents` is `true`.",
+					},
+				}),
+			},
+		},
+	}, nil
+}
+
+func (policiesDataSourceType) NewDataSource(_ context.Context, p tfsdk.Provider) (tfsdk.DataSource, diag.Diagnostics) {
+	pr, ok := p.(*provider)
+	if !ok {
+		return nil, errorConvertingProvider(p)
+	}
+
+	return &policiesDataSource{
+		clientSet: pr.clientSet,
+	}, nil
+}
+
+func (d policiesDataSource) Read(ctx context.Context, req tfsdk.ReadDataSourceRequest, resp *tfsdk.ReadDataSourceResponse) {
+	tflog.Info(ctx, "Reading Policies data source")
+	ctx, span := startOperation(ctx, "lifeomic_policies", "read")
+	defer func() { endOperation(span, resp.Diagnostics) }()
+
+	var state policyList
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var nameRegex *regexp.Regexp
+	if !state.NameRegex.Null {
+		var err error
+		if nameRegex, err = regexp.Compile(state.NameRegex.Value); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("name_regex"), "Invalid regular expression", err.Error())
+			return
+		}
+	}
+
+	clientSet, diags := d.clientSet.forResource(ctx, state.AccountID)
+	if diags.HasError() {
+		resp.Diagnostics.Append(diags...)
+		return
+	}
+
+	policies, err := client.CollectAll(ctx, clientSet.Policies.List)
+	if err != nil {
+		resp.Diagnostics.Append(apiErrorDiagnostic("failed to list policies", err))
+		return
+	}
+	policies = filterPolicies(policies, state.NamePrefix.Value, nameRegex)
+
+	state.Names = make([]string, len(policies))
+	state.Policies = make([]policyListItem, len(policies))
+	for i, p := range policies {
+		state.Names[i] = p.Name
+		state.Policies[i] = policyListItem{
+			Name:       types.String{Value: p.Name},
+			PolicyJSON: types.String{Null: true},
+		}
+		if state.IncludeDocuments.Value {
+			document, err := json.Marshal(p.Policy)
+			if err != nil {
+				resp.Diagnostics.AddError("failed to encode policy document", err.Error())
+				return
+			}
+			state.Policies[i].PolicyJSON = types.String{Value: string(document)}
+		}
+	}
+	state.ID = types.String{Value: fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(state.Names, ","))))}
+
+	tflog.Info(ctx, "Listed policies", map[string]any{"policies": state.Names})
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+}
+
+// filterPolicies returns the policies whose names start with prefix and
+// match nameRegex, if it isn't nil.
+func filterPolicies(policies []client.Policy, prefix string, nameRegex *regexp.Regexp) []client.Policy {
+	filtered := make([]client.Policy, 0, len(policies))
+	for _, p := range policies {
+		if !strings.HasPrefix(p.Name, prefix) {
+			continue
+		}
+		if nameRegex != nil && !nameRegex.MatchString(p.Name) {
+			continue
+		}
+		filtered = append(filtered, p)
+	}
+	return filtered
+}
+
+type regexValidator struct {
+	terraformDescriptionNoop
+}
+
+// Validate ensures that a string attribute is a valid regular expression.
+func (v *regexValidator) Validate(ctx context.Context, req tfsdk.ValidateAttributeRequest, resp *tfsdk.ValidateAttributeResponse) {
+	var value types.String
+	resp.Diagnostics.Append(tfsdk.ValueAs(ctx, req.AttributeConfig, &value)...)
+	if resp.Diagnostics.HasError() || value.Unknown || value.Null {
+		return
+	}
+
+	if _, err := regexp.Compile(value.Value); err != nil {
+		resp.Diagnostics.AddAttributeError(req.AttributePath, "Invalid regular expression", err.Error())
+	}
+}
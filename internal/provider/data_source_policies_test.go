@@ -0,0 +1,88 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccPHCPolicies_basic(t *testing.T) {
+	t.Parallel()
+	name := randomResourceName(t, 8)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 testAccPreCheck(t),
+		ProtoV6ProviderFactories: testAccProviderFactories,
+
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPHCPolicy_staticRule(name) + fmt.Sprintf(`
+
+data "lifeomic_policies" "test" {
+  name_prefix       = %q
+  include_documents = true
+
+  depends_on = [lifeomic_policy.test]
+}
+`, name),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.lifeomic_policies.test", "names.#", "1"),
+					resource.TestCheckResourceAttr("data.lifeomic_policies.test", "names.0", name),
+					resource.TestCheckResourceAttr("data.lifeomic_policies.test", "policies.0.name", name),
+					resource.TestCheckResourceAttr("data.lifeomic_policies.test", "policies.0.policy_json",
+						`{"rules":{"readData":true}}`),
+				),
+			},
+		},
+	})
+}
+
+func TestFilterPolicies(t *testing.T) {
+	policies := []client.Policy{{Name: "team-readers"}, {Name: "team-writers"}, {Name: "admins"}}
+
+	for _, fixture := range []struct {
+		name          string
+		prefix        string
+		nameRegex     *regexp.Regexp
+		expectedValue []string
+	}{
+		{
+			name:          "should list every policy without filters",
+			expectedValue: []string{"team-readers", "team-writers", "admins"},
+		},
+		{
+			name:          "should filter by prefix",
+			prefix:        "team-",
+			expectedValue: []string{"team-readers", "team-writers"},
+		},
+		{
+			name:          "should filter by regex",
+			nameRegex:     regexp.MustCompile("writers|admins"),
+			expectedValue: []string{"team-writers", "admins"},
+		},
+		{
+			name:          "should filter by both prefix and regex",
+			prefix:        "team-",
+			nameRegex:     regexp.MustCompile("writers|admins"),
+			expectedValue: []string{"team-writers"},
+		},
+		{
+			name:          "should return no policies when none match",
+			prefix:        "other-",
+			expectedValue: []string{},
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			filtered := filterPolicies(policies, fixture.prefix, fixture.nameRegex)
+			names := make([]string, len(filtered))
+			for i, p := range filtered {
+				names[i] = p.Name
+			}
+			assert.Equal(t, fixture.expectedValue, names)
+		})
+	}
+}
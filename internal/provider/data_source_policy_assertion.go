@@ -0,0 +1,172 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/client"
+)
+
+// policyAssertion represents the state of a lifeomic_policy_test data
+// source.
+type policyAssertion struct {
+	ID         types.String     `tfsdk:"id"`
+	PolicyJSON types.String     `tfsdk:"policy_json"`
+	Case       []policyTestCase `tfsdk:"case"`
+}
+
+// policyTestCase represents the state of a lifeomic_policy_test data source's
+// case block.
+type policyTestCase struct {
+	Name      types.String        `tfsdk:"name"`
+	Operation types.String        `tfsdk:"operation"`
+	User      map[string][]string `tfsdk:"user"`
+	Resource  map[string][]string `tfsdk:"resource"`
+	Allowed   types.Bool          `tfsdk:"allowed"`
+}
+
+// policyAssertionDataSource implements tfsdk.DataSource.
+type policyAssertionDataSource struct{}
+
+// policyAssertionDataSourceType implements tfsdk.DataSourceType.
+type policyAssertionDataSourceType struct{}
+
+func (policyAssertionDataSourceType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	return tfsdk.Schema{
+		Description: fmt.Sprintf("`lifeomic_policy_test` evaluates test cases against an [ABAC policy](%s) document "+
+			"without calling the PHC API. Reading the data source fails if any test case doesn't hold.", policyDocsURL),
+		Attributes: map[string]tfsdk.Attribute{
+			"id": {
+				Type:        types.StringType,
+				Computed:    true,
+				Description: "A hash of the tested policy document.",
+			},
+			"policy_json": {
+				Type:     types.StringType,
+				Required: true,
+				Description: "The JSON encoded ABAC policy document to test, e.g. from `lifeomic_policy.policy_json` " +
+					"or `lifeomic_policy_document.json`.",
+				Validators: []tfsdk.AttributeValidator{
+					&policyJSONValidator{},
+				},
+			},
+		},
+		Blocks: map[string]tfsdk.Block{
+			"case": {
+				Description: "A test case asserting whether an operation is allowed for the given attributes.",
+				NestingMode: tfsdk.BlockNestingModeList,
+				MinItems:    1,
+				Attributes: map[string]tfsdk.Attribute{
+					"name": {
+						Type:        types.StringType,
+						Optional:    true,
+						Description: "A name describing this test case.",
+					},
+					"operation": {
+						Type:        types.StringType,
+						Required:    true,
+						Description: fmt.Sprintf("The [operation](%s) to evaluate.", policyOperationDocsURL),
+					},
+					"user": {
+						Type:     types.MapType{ElemType: types.ListType{ElemType: types.StringType}},
+						Optional: true,
+						Description: fmt.Sprintf("The `user.*` [attributes](%s) of the request. "+
+							"Single valued attributes are lists with one element.", policyAttributeDocsURL),
+					},
+					"resource": {
+						Type:     types.MapType{ElemType: types.ListType{ElemType: types.StringType}},
+						Optional: true,
+						Description: fmt.Sprintf("The `resource.*` [attributes](%s) of the request. "+
+							"Single valued attributes are lists with one element.", policyAttributeDocsURL),
+					},
+					"allowed": {
+						Type:        types.BoolType,
+						Required:    true,
+						Description: "Whether the operation is expected to be allowed.",
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func (policyAssertionDataSourceType) NewDataSource(_ context.Context, _ tfsdk.Provider) (tfsdk.DataSource, diag.Diagnostics) {
+	return &policyAssertionDataSource{}, nil
+}
+
+func (d policyAssertionDataSource) Read(ctx context.Context, req tfsdk.ReadDataSourceRequest, resp *tfsdk.ReadDataSourceResponse) {
+	tflog.Info(ctx, "Reading Policy Test data source")
+
+	var config policyAssertion
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	document, err := parsePolicyJSON(config.PolicyJSON.Value)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("policy_json"), "Invalid policy document", err.Error())
+		return
+	}
+
+	resp.Diagnostics.Append(runPolicyTestCases(document, config.Case)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	config.ID = types.String{Value: fmt.Sprintf("%x", sha256.Sum256([]byte(config.PolicyJSON.Value)))}
+	resp.Diagnostics.Append(resp.State.Set(ctx, config)...)
+}
+
+// runPolicyTestCases evaluates each test case against the policy document,
+// returning an error for every case that doesn't hold.
+func runPolicyTestCases(document *client.PolicyDocument, cases []policyTestCase) (diags diag.Diagnostics) {
+	for i, testCase := range cases {
+		casePath := path.Root("case").AtListIndex(i)
+
+		name := testCase.Name.Value
+		if testCase.Name.Null {
+			name = fmt.Sprintf("case %d", i)
+		}
+
+		attributes := make(client.Attributes, len(testCase.User)+len(testCase.Resource))
+		for key, values := range testCase.User {
+			attributes["user."+key] = values
+		}
+		for key, values := range testCase.Resource {
+			attributes["resource."+key] = values
+		}
+
+		operation := testCase.Operation.Value
+		decision, err := document.Evaluate(operation, attributes)
+		if err != nil {
+			diags.AddAttributeError(casePath, fmt.Sprintf("Failed to evaluate policy test %q", name), err.Error())
+			continue
+		}
+
+		if decision.Allowed == testCase.Allowed.Value {
+			continue
+		}
+
+		if decision.Allowed {
+			reason := "a static rule"
+			if decision.Rule != nil {
+				reason = fmt.Sprintf("rule %d", decision.RuleIndex)
+			}
+			diags.AddAttributeError(casePath, fmt.Sprintf("Policy test %q failed", name),
+				fmt.Sprintf("Expected %q to be denied, but it was allowed by %s", operation, reason))
+			continue
+		}
+
+		diags.AddAttributeError(casePath, fmt.Sprintf("Policy test %q failed", name),
+			fmt.Sprintf("Expected %q to be allowed, but no rule matched", operation))
+	}
+
+	return
+}
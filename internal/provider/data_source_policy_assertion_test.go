@@ -0,0 +1,145 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccPHCPolicyTest_basic(t *testing.T) {
+	t.Parallel()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 testAccPreCheck(t),
+		ProtoV6ProviderFactories: testAccProviderFactories,
+
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPHCPolicyTest(true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.lifeomic_policy_test.test", "id"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPHCPolicyTest_failingCase(t *testing.T) {
+	t.Parallel()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 testAccPreCheck(t),
+		ProtoV6ProviderFactories: testAccProviderFactories,
+
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccPHCPolicyTest(false),
+				ExpectError: regexp.MustCompile(`Policy test "admins can read" failed`),
+			},
+		},
+	})
+}
+
+func TestRunPolicyTestCases(t *testing.T) {
+	document, err := parsePolicyJSON(`{"rules": {
+		"readData": [{"user.groups": {"comparison": "includes", "value": "admin"}}],
+		"writeData": [{"resource.owner": {"comparison": "equals", "target": "user.id"}}]
+	}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, fixture := range []struct {
+		name        string
+		testCase    policyTestCase
+		expectedErr string
+	}{
+		{
+			name: "should pass allowed case",
+			testCase: policyTestCase{
+				Operation: types.String{Value: "readData"},
+				User:      map[string][]string{"groups": {"admin", "doctor"}},
+				Allowed:   types.Bool{Value: true},
+			},
+		},
+		{
+			name: "should pass denied case",
+			testCase: policyTestCase{
+				Name:      types.String{Value: "others can't write"},
+				Operation: types.String{Value: "writeData"},
+				User:      map[string][]string{"id": {"bob"}},
+				Resource:  map[string][]string{"owner": {"alice"}},
+				Allowed:   types.Bool{Value: false},
+			},
+		},
+		{
+			name: "should fail unexpectedly allowed case",
+			testCase: policyTestCase{
+				Name:      types.String{Value: "owners can't write"},
+				Operation: types.String{Value: "writeData"},
+				User:      map[string][]string{"id": {"bob"}},
+				Resource:  map[string][]string{"owner": {"bob"}},
+				Allowed:   types.Bool{Value: false},
+			},
+			expectedErr: `Expected "writeData" to be denied, but it was allowed by rule 0`,
+		},
+		{
+			name: "should fail unexpectedly denied case",
+			testCase: policyTestCase{
+				Name:      types.String{Null: true},
+				Operation: types.String{Value: "deleteData"},
+				Allowed:   types.Bool{Value: true},
+			},
+			expectedErr: `Expected "deleteData" to be allowed, but no rule matched`,
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			diags := runPolicyTestCases(document, []policyTestCase{fixture.testCase})
+			if fixture.expectedErr == "" {
+				assert.False(t, diags.HasError(), "unexpected error: %v", diags)
+				return
+			}
+
+			if assert.True(t, diags.HasError()) {
+				assert.Equal(t, fixture.expectedErr, diags[0].Detail())
+			}
+		})
+	}
+}
+
+func testAccPHCPolicyTest(adminsAllowed bool) string {
+	config := `data "lifeomic_policy_document" "test" {
+  rule {
+    operation = "readData"
+
+    comparison {
+      subject = "user.groups"
+      type    = "includes"
+      value   = "admin"
+    }
+  }
+}
+
+data "lifeomic_policy_test" "test" {
+  policy_json = data.lifeomic_policy_document.test.json
+
+  case {
+    name      = "admins can read"
+    operation = "readData"
+    user      = { groups = ["admin"] }
+    allowed   = %t
+  }
+
+  case {
+    name      = "others can't read"
+    operation = "readData"
+    user      = { groups = ["doctor"] }
+    allowed   = false
+  }
+}`
+	return fmt.Sprintf(config, adminsAllowed)
+}
@@ -0,0 +1,155 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/client"
+)
+
+// policyDocument represents the state of a lifeomic_policy_document data
+// source.
+type policyDocument struct {
+	ID                      types.String `tfsdk:"id"`
+	JSON                    types.String `tfsdk:"json"`
+	SourcePolicyDocuments   []string     `tfsdk:"source_policy_documents"`
+	OverridePolicyDocuments []string     `tfsdk:"override_policy_documents"`
+	Rule                    []policyRule `tfsdk:"rule"`
+}
+
+// policyDocumentDataSource implements tfsdk.DataSource.
+type policyDocumentDataSource struct{}
+
+// policyDocumentDataSourceType implements tfsdk.DataSourceType.
+type policyDocumentDataSourceType struct{}
+
+func (policyDocumentDataSourceType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	return tfsdk.Schema{
+		Description: fmt.Sprintf("`lifeomic_policy_document` renders an [ABAC policy](%s) document as JSON.", policyDocsURL),
+		Attributes: map[string]tfsdk.Attribute{
+			"id": {
+				Type:        types.StringType,
+				Computed:    true,
+				Description: "A hash of the rendered policy document.",
+			},
+			"json": {
+				Type:        types.StringType,
+				Computed:    true,
+				Description: "The canonical JSON encoding of the rendered ABAC policy document.",
+			},
+			"source_policy_documents": {
+				Type:     types.ListType{ElemType: types.StringType},
+				Optional: true,
+				Description: "JSON encoded ABAC policy documents to merge into this document. " +
+					"An operation may only be defined by a single source document. " +
+					"Rules declared in this data source replace rules for the same operation.",
+			},
+			"override_policy_documents": {
+				Type:     types.ListType{ElemType: types.StringType},
+				Optional: true,
+				Description: "JSON encoded ABAC policy documents whose rules replace the rules for the same operation " +
+					"in this document. Documents are applied in order, later documents taking precedence.",
+			},
+		},
+		Blocks: map[string]tfsdk.Block{
+			"rule": policyRuleBlock(),
+		},
+	}, nil
+}
+
+func (policyDocumentDataSourceType) NewDataSource(_ context.Context, _ tfsdk.Provider) (tfsdk.DataSource, diag.Diagnostics) {
+	return &policyDocumentDataSource{}, nil
+}
+
+func (d policyDocumentDataSource) Read(ctx context.Context, req tfsdk.ReadDataSourceRequest, resp *tfsdk.ReadDataSourceResponse) {
+	tflog.Info(ctx, "Reading Policy Document data source")
+
+	var config policyDocument
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	rules := policyRulesFromBlocks(ctx, path.Root("rule"), config.Rule)
+	document, diags := mergePolicyDocuments(config.SourcePolicyDocuments, rules, config.OverridePolicyDocuments)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	rendered, err := json.Marshal(document)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to encode policy document", err.Error())
+		return
+	}
+
+	config.JSON = types.String{Value: string(rendered)}
+	config.ID = types.String{Value: fmt.Sprintf("%x", sha256.Sum256(rendered))}
+	resp.Diagnostics.Append(resp.State.Set(ctx, config)...)
+}
+
+// mergePolicyDocuments builds an ABAC policy document from the given source
+// documents, rules, and override documents.
+//
+// Source documents are merged first and must not define the same operation
+// more than once. The given rules then replace any source rules for the same
+// operation. Finally, each override document replaces the rules for the
+// operations it defines.
+func mergePolicyDocuments(sources []string, rules client.PolicyRules, overrides []string) (document *client.PolicyDocument, diags diag.Diagnostics) {
+	merged := make(client.PolicyRules)
+
+	// Track which source document defined each operation to report
+	// conflicts.
+	definedBy := make(map[string]int)
+	for i, source := range sources {
+		sourcePath := path.Root("source_policy_documents").AtListIndex(i)
+
+		parsed, err := parsePolicyJSON(source)
+		if err != nil {
+			diags.AddAttributeError(sourcePath, "Invalid policy document", err.Error())
+			continue
+		}
+
+		for operation, rule := range parsed.Rules {
+			if location, ok := definedBy[operation]; ok {
+				diags.AddAttributeError(sourcePath,
+					fmt.Sprintf("Duplicate rule for operation %q", operation),
+					fmt.Sprintf("Operation %q is already defined by the source document at index %d", operation, location))
+				continue
+			}
+
+			definedBy[operation] = i
+			merged[operation] = rule
+		}
+	}
+
+	for operation, rule := range rules {
+		merged[operation] = rule
+	}
+
+	for i, override := range overrides {
+		parsed, err := parsePolicyJSON(override)
+		if err != nil {
+			diags.AddAttributeError(path.Root("override_policy_documents").AtListIndex(i),
+				"Invalid policy document", err.Error())
+			continue
+		}
+
+		for operation, rule := range parsed.Rules {
+			merged[operation] = rule
+		}
+	}
+
+	if diags.HasError() {
+		return
+	}
+
+	return &client.PolicyDocument{Rules: merged}, diags
+}
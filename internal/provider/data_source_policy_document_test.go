@@ -0,0 +1,144 @@
+package provider
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/client"
+	"github.com/stretchr/testify/assert"
+)
+
+var testPolicyDocumentDataSourceName = "data.lifeomic_policy_document.test"
+
+func TestAccPHCPolicyDocument_basic(t *testing.T) {
+	t.Parallel()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 testAccPreCheck(t),
+		ProtoV6ProviderFactories: testAccProviderFactories,
+
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPHCPolicyDocument_basic,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(testPolicyDocumentDataSourceName, "json",
+						`{"rules":{"readData":[{"user.groups":{"comparison":"includes","value":"admin"}}],"writeData":true}}`),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPHCPolicyDocument_override(t *testing.T) {
+	t.Parallel()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 testAccPreCheck(t),
+		ProtoV6ProviderFactories: testAccProviderFactories,
+
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPHCPolicyDocument_override,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(testPolicyDocumentDataSourceName, "json",
+						`{"rules":{"readData":[{"user.id":{"comparison":"equals","value":"bob"}}],"writeData":true}}`),
+				),
+			},
+		},
+	})
+}
+
+func TestMergePolicyDocuments(t *testing.T) {
+	for _, fixture := range []struct {
+		name          string
+		sources       []string
+		rules         client.PolicyRules
+		overrides     []string
+		expectedValue string
+		expectedErr   string
+	}{
+		{
+			name:          "should merge source documents",
+			sources:       []string{`{"rules": {"readData": true}}`, `{"rules": {"writeData": true}}`},
+			expectedValue: `{"rules":{"readData":true,"writeData":true}}`,
+		},
+		{
+			name:    "should replace source rules with rule blocks",
+			sources: []string{`{"rules": {"readData": true, "writeData": true}}`},
+			rules: client.PolicyRules{"readData": client.RuleMappings{
+				{"user.id": client.ValueComparison{Comparison: client.ComparisonEquals, Value: "bob"}},
+			}},
+			expectedValue: `{"rules":{"readData":[{"user.id":{"comparison":"equals","value":"bob"}}],"writeData":true}}`,
+		},
+		{
+			name:          "should apply overrides in order",
+			rules:         client.PolicyRules{"readData": client.StaticRule(true)},
+			overrides:     []string{`{"rules": {"readData": false}}`, `{"rules": {"readData": [{"user.id": {"comparison": "equals", "value": "bob"}}]}}`},
+			expectedValue: `{"rules":{"readData":[{"user.id":{"comparison":"equals","value":"bob"}}]}}`,
+		},
+		{
+			name:        "should reject duplicate source operations",
+			sources:     []string{`{"rules": {"readData": true}}`, `{"rules": {"readData": false}}`},
+			expectedErr: `Duplicate rule for operation "readData"`,
+		},
+		{
+			name:        "should reject invalid override documents",
+			overrides:   []string{`{"rules": {"readData": "yes"}}`},
+			expectedErr: "Invalid policy document",
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			document, diags := mergePolicyDocuments(fixture.sources, fixture.rules, fixture.overrides)
+			if diags.HasError() {
+				if fixture.expectedErr == "" {
+					t.Errorf("unexpected error: %v", diags)
+					return
+				}
+				assert.Contains(t, diags[0].Summary(), fixture.expectedErr)
+				return
+			} else if fixture.expectedErr != "" {
+				t.Errorf("expected error matching: %s; got nil", fixture.expectedErr)
+				return
+			}
+
+			value, err := json.Marshal(document)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, fixture.expectedValue, string(value))
+		})
+	}
+}
+
+const testAccPHCPolicyDocument_basic = `data "lifeomic_policy_document" "test" {
+  source_policy_documents = [jsonencode({ rules = { writeData = true } })]
+
+  rule {
+    operation = "readData"
+
+    comparison {
+      subject = "user.groups"
+      type    = "includes"
+      value   = "admin"
+    }
+  }
+}`
+
+const testAccPHCPolicyDocument_override = `data "lifeomic_policy_document" "test" {
+  override_policy_documents = [jsonencode({
+    rules = {
+      readData = [{ "user.id" = { comparison = "equals", value = "bob" } }]
+    }
+  })]
+
+  rule {
+    operation = "readData"
+    allowed   = true
+  }
+
+  rule {
+    operation = "writeData"
+    allowed   = true
+  }
+}`
@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/client"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/gqlclient"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/lambda"
+)
+
+// apiErrorDiagnostic converts an error returned by the PHC API into a
+// diagnostic, explaining how to resolve errors the user can act on.
+func apiErrorDiagnostic(summary string, err error) diag.Diagnostic {
+	switch {
+	case client.IsRateLimited(err):
+		return diag.NewErrorDiagnostic(summary,
+			fmt.Sprintf("The PHC API is rate limiting requests. Try again later, "+
+				"or apply with a lower -parallelism.\n\n%s", err))
+	case gqlclient.IsForbidden(err):
+		return diag.NewErrorDiagnostic(summary,
+			fmt.Sprintf("The provider's credentials aren't allowed to make this request. "+
+				"Check the account_id and the permissions of the token.\n\n%s", err))
+	case errors.Is(err, lambda.ErrMissingCredentials):
+		return diag.NewErrorDiagnostic(summary,
+			fmt.Sprintf("Invoking lambda functions requires AWS credentials. Set $AWS_ACCESS_KEY_ID and "+
+				"$AWS_SECRET_ACCESS_KEY or $AWS_PROFILE, or unset use_lambda and $%s.\n\n%s", client.UseLambdaEnvVar, err))
+	}
+	return diag.NewErrorDiagnostic(summary, err.Error())
+}
+
+// graphQLErrorDiagnostics converts an error returned by a GraphQL service into
+// diagnostics. BAD_USER_INPUT errors are reported for the attribute which
+// inputAttributes maps the invalid input field to, if there is one.
+func graphQLErrorDiagnostics(summary string, err error, inputAttributes map[string]string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	var errs gqlclient.Errors
+	if !errors.As(err, &errs) {
+		diags.Append(apiErrorDiagnostic(summary, err))
+		return diags
+	}
+
+	for _, e := range errs {
+		attribute, ok := inputAttribute(e.Fields, inputAttributes)
+		switch {
+		case e.Code == gqlclient.ErrorCodeBadUserInput && ok:
+			diags.AddAttributeError(path.Root(attribute), summary, e.Message)
+		default:
+			diags.Append(apiErrorDiagnostic(summary, e))
+		}
+	}
+	return diags
+}
+
+// inputAttribute returns the attribute the first of the dot separated input
+// fields is set from, matching the innermost field name in inputAttributes,
+// e.g. "imageUrl" for "input.sourceInfo.imageUrl".
+func inputAttribute(fields []string, inputAttributes map[string]string) (string, bool) {
+	for _, field := range fields {
+		names := strings.Split(field, ".")
+		for i := len(names) - 1; i >= 0; i-- {
+			if attribute, ok := inputAttributes[names[i]]; ok {
+				return attribute, true
+			}
+		}
+	}
+	return "", false
+}
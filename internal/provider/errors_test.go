@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/client"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/gqlclient"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/lambda"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIErrorDiagnostic(t *testing.T) {
+	for _, fixture := range []struct {
+		name           string
+		err            error
+		expectedDetail string
+	}{
+		{
+			name:           "should explain rate limiting",
+			err:            fmt.Errorf("wrapped: %w", &client.APIError{StatusCode: http.StatusTooManyRequests}),
+			expectedDetail: "The PHC API is rate limiting requests",
+		},
+		{
+			name:           "should explain forbidden requests",
+			err:            &client.APIError{StatusCode: http.StatusForbidden, Message: "Forbidden"},
+			expectedDetail: "Check the account_id and the permissions of the token.\n\n403 Forbidden: Forbidden",
+		},
+		{
+			name:           "should explain missing AWS credentials",
+			err:            fmt.Errorf("%w: no EC2 IMDS role found", lambda.ErrMissingCredentials),
+			expectedDetail: "Invoking lambda functions requires AWS credentials.",
+		},
+		{
+			name:           "should use the error as the detail",
+			err:            errors.New("connection refused"),
+			expectedDetail: "connection refused",
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			diagnostic := apiErrorDiagnostic("failed to get policy", fixture.err)
+			assert.Equal(t, "failed to get policy", diagnostic.Summary())
+			assert.Contains(t, diagnostic.Detail(), fixture.expectedDetail)
+		})
+	}
+}
+
+func TestGraphQLErrorDiagnostics(t *testing.T) {
+	inputAttributes := map[string]string{"imageUrl": "image_url"}
+
+	for _, fixture := range []struct {
+		name          string
+		err           error
+		expectedValue diag.Diagnostics
+	}{
+		{
+			name: "should report bad user input for attributes",
+			err: fmt.Errorf("wrapped: %w", gqlclient.Errors{{
+				Operation: "SetWellnessOfferingDraftModuleSource",
+				Message:   "imageUrl must be a URL",
+				Code:      gqlclient.ErrorCodeBadUserInput,
+				Fields:    []string{"input.sourceInfo.imageUrl"},
+			}}),
+			expectedValue: diag.Diagnostics{
+				diag.NewAttributeErrorDiagnostic(path.Root("image_url"), "failed to set source", "imageUrl must be a URL"),
+			},
+		},
+		{
+			name: "should report other errors for the resource",
+			err: gqlclient.Errors{
+				{Operation: "SetWellnessOfferingDraftModuleSource", Message: "title is too long", Code: gqlclient.ErrorCodeBadUserInput},
+				{Operation: "SetWellnessOfferingDraftModuleSource", Message: "internal error"},
+			},
+			expectedValue: diag.Diagnostics{
+				diag.NewErrorDiagnostic("failed to set source", "SetWellnessOfferingDraftModuleSource: title is too long [BAD_USER_INPUT]"),
+				diag.NewErrorDiagnostic("failed to set source", "SetWellnessOfferingDraftModuleSource: internal error"),
+			},
+		},
+		{
+			name: "should report HTTP errors",
+			err:  &client.APIError{StatusCode: http.StatusBadGateway},
+			expectedValue: diag.Diagnostics{
+				diag.NewErrorDiagnostic("failed to set source", "502 Bad Gateway"),
+			},
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			assert.Equal(t, fixture.expectedValue, graphQLErrorDiagnostics("failed to set source", fixture.err, inputAttributes))
+		})
+	}
+}
@@ -2,8 +2,12 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
@@ -12,10 +16,9 @@ import (
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/lifeomic/terraform-provider-lifeomic/internal/client"
 	"github.com/lifeomic/terraform-provider-lifeomic/internal/common"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/lambda"
 )
 
-const useLambdaEnvVar = "LIFEOMIC_USE_LAMBDA"
-
 type provider struct {
 	clientSet  *clientSet
 	configured bool
@@ -25,9 +28,39 @@ type providerData struct {
 	AccountID types.String `tfsdk:"account_id"`
 	Token     types.String `tfsdk:"token"`
 	Host      types.String `tfsdk:"host"`
+	Region    types.String `tfsdk:"region"`
+	Endpoints types.Map    `tfsdk:"endpoints"`
 	Headers   types.Map    `tfsdk:"headers"`
+
+	UserID     types.String `tfsdk:"user_id"`
+	PolicyJSON types.String `tfsdk:"policy_json"`
+
+	UseLambda       types.Bool   `tfsdk:"use_lambda"`
+	LambdaQualifier types.String `tfsdk:"lambda_qualifier"`
+	LambdaFunctions types.Map    `tfsdk:"lambda_functions"`
+
+	Profile           types.String   `tfsdk:"profile"`
+	ConfigFile        types.String   `tfsdk:"config_file"`
+	TokenFile         types.String   `tfsdk:"token_file"`
+	CredentialProcess types.String   `tfsdk:"credential_process"`
+	Auth              []providerAuth `tfsdk:"auth"`
+
+	CABundleFile          types.String `tfsdk:"ca_bundle_file"`
+	ClientCertificateFile types.String `tfsdk:"client_certificate_file"`
+	ClientKeyFile         types.String `tfsdk:"client_key_file"`
+	ProxyURL              types.String `tfsdk:"proxy_url"`
+	ConnectTimeout        types.String `tfsdk:"connect_timeout"`
+	RequestTimeout        types.String `tfsdk:"request_timeout"`
+
+	Retry     []providerRetry     `tfsdk:"retry"`
+	RateLimit []providerRateLimit `tfsdk:"rate_limit"`
 }
 
+// serviceNames are the PHC services the provider calls, whose endpoints and
+// lambda functions may be mapped by the endpoints and lambda_functions
+// provider attributes.
+var serviceNames = []string{"account-service", "app-store-service", "marketplace-service"}
+
 func New() tfsdk.Provider {
 	return &provider{}
 }
@@ -48,12 +81,52 @@ func (p *provider) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics)
 				Type:        types.StringType,
 				Sensitive:   true,
 				Optional:    true,
-				Description: providerAttributeDescription("The token to use for authenticating with the PHC API", client.AuthTokenEnvVar),
+				Description: providerAttributeDescription("The token to use for authenticating with the PHC API. Conflicts with the `auth` block", client.AuthTokenEnvVar),
+			},
+			"token_file": {
+				Type:     types.StringType,
+				Optional: true,
+				Description: providerAttributeDescription("The path of a file containing the token to use for authenticating "+
+					"with the PHC API. The file is read again if the token is rejected, so it may be rotated by other processes", client.TokenFileEnvVar),
+			},
+			"credential_process": {
+				Type:     types.StringType,
+				Optional: true,
+				Description: providerAttributeDescription("A command which prints the token to use for authenticating with "+
+					"the PHC API, either on its own or as JSON such as `{\"token\": \"...\", \"expiration\": \"2022-08-01T12:00:00Z\"}`. "+
+					"The token is reused until it's about to expire", client.CredentialProcessEnvVar),
+			},
+			"profile": {
+				Type:     types.StringType,
+				Optional: true,
+				Description: providerAttributeDescription("The profile of the LifeOmic config file to use. Profiles may set "+
+					"`account`, `host`, `region`, and one of `token`, `token_file`, or `credential_process`, "+
+					"which are used when the corresponding provider values aren't set. Defaults to `default`", client.ProfileEnvVar),
+			},
+			"config_file": {
+				Type:     types.StringType,
+				Optional: true,
+				Description: providerAttributeDescription("The path of the LifeOmic config file. Defaults to `~/.lifeomic/config.yaml`",
+					client.ConfigFileEnvVar),
 			},
 			"host": {
-				Type:        types.StringType,
-				Optional:    true,
-				Description: providerAttributeDescription("The PHC API host to communicate with.", client.HostEnvVar),
+				Type:     types.StringType,
+				Optional: true,
+				Description: providerAttributeDescription("The PHC API host to communicate with, e.g. `api.us.lifeomic.com`. "+
+					"The endpoints of other services are derived from it, e.g. `marketplace.us.lifeomic.com`", client.HostEnvVar),
+			},
+			"region": {
+				Type:     types.StringType,
+				Optional: true,
+				Description: providerAttributeDescription(fmt.Sprintf("The named PHC region (%s) whose endpoints to use. "+
+					"Conflicts with `host`", "`"+strings.Join(client.Regions(), "`, `")+"`"), client.RegionEnvVar),
+			},
+			"endpoints": {
+				Type:     types.MapType{ElemType: types.StringType},
+				Optional: true,
+				Description: fmt.Sprintf("Maps PHC services (%s) to the URLs of their endpoints, overriding the URLs "+
+					"derived from `host` or `region`, e.g. `https://marketplace.us.lifeomic.com/v1/marketplace/authenticated/graphql`.",
+					"`"+strings.Join(serviceNames, "`, `")+"`"),
 			},
 			"headers": {
 				Type:     types.MapType{ElemType: types.StringType},
@@ -62,6 +135,89 @@ func (p *provider) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics)
 					"You can also use the LIFEOMIC_HEADERS environment variable as stringified JSON. " +
 					"Environment variables take precedent over other values",
 			},
+			"user_id": {
+				Type:     types.StringType,
+				Optional: true,
+				Description: providerAttributeDescription("The ID of the PHC user to make requests on behalf of, "+
+					"sent in the `LifeOmic-User` header", client.UserIDEnvVar),
+			},
+			"policy_json": {
+				Type:      types.StringType,
+				Optional:  true,
+				Sensitive: true,
+				Description: providerAttributeDescription(fmt.Sprintf("A JSON encoded [ABAC policy](%s) document, "+
+					"e.g. from `lifeomic_policy_document.json`, sent in the `LifeOmic-Policy` header to grant requests "+
+					"the permissions it describes", policyDocsURL), client.PolicyEnvVar),
+				Validators: []tfsdk.AttributeValidator{
+					&policyJSONValidator{},
+				},
+			},
+			"use_lambda": {
+				Type:     types.BoolType,
+				Optional: true,
+				Description: providerAttributeDescription("Whether to invoke the PHC services' lambda functions directly "+
+					"instead of going through the API gateway. This requires AWS credentials with permission to invoke them", client.UseLambdaEnvVar),
+			},
+			"lambda_qualifier": {
+				Type:     types.StringType,
+				Optional: true,
+				Description: fmt.Sprintf("The version or alias of the lambda functions to invoke when `lambda_functions` "+
+					"doesn't specify one. Defaults to `%s`.", lambda.DefaultQualifier),
+			},
+			"lambda_functions": {
+				Type:     types.MapType{ElemType: types.StringType},
+				Optional: true,
+				Description: fmt.Sprintf("Maps PHC services (%s) to the lambda functions to invoke for them, "+
+					"given as `function`, `function:qualifier` or `lambda://function:qualifier`. "+
+					"Services which aren't mapped invoke the function of the same name.", "`"+strings.Join(serviceNames, "`, `")+"`"),
+			},
+			"ca_bundle_file": {
+				Type:     types.StringType,
+				Optional: true,
+				Description: providerAttributeDescription("The path of a PEM file of CA certificates to trust in addition "+
+					"to the system's, e.g. those of an intercepting proxy", client.CABundleEnvVar),
+			},
+			"client_certificate_file": {
+				Type:     types.StringType,
+				Optional: true,
+				Description: providerAttributeDescription("The path of a PEM encoded client certificate to authenticate "+
+					"with using mutual TLS. Requires `client_key_file`", client.ClientCertificateEnvVar),
+			},
+			"client_key_file": {
+				Type:     types.StringType,
+				Optional: true,
+				Description: providerAttributeDescription("The path of the PEM encoded private key of "+
+					"`client_certificate_file`", client.ClientKeyEnvVar),
+			},
+			"proxy_url": {
+				Type:     types.StringType,
+				Optional: true,
+				Description: "The URL of the proxy to send requests through, e.g. `http://proxy.example.com:3128`. " +
+					"Defaults to `$HTTPS_PROXY`, respecting `$NO_PROXY`.",
+			},
+			"connect_timeout": {
+				Type:     types.StringType,
+				Optional: true,
+				Description: "How long establishing a connection, including the TLS handshake, may take, " +
+					"as a duration such as `10s`.",
+				Validators: []tfsdk.AttributeValidator{
+					&durationValidator{},
+				},
+			},
+			"request_timeout": {
+				Type:     types.StringType,
+				Optional: true,
+				Description: "How long each attempt of a request may take, including reading the response, " +
+					"as a duration such as `1m`. Attempts which time out are retried. Unlimited if not set.",
+				Validators: []tfsdk.AttributeValidator{
+					&durationValidator{},
+				},
+			},
+		},
+		Blocks: map[string]tfsdk.Block{
+			"auth":       authBlock(),
+			"retry":      retryBlock(),
+			"rate_limit": rateLimitBlock(),
 		},
 	}, nil
 }
@@ -76,7 +232,27 @@ func (p *provider) Configure(ctx context.Context, req tfsdk.ConfigureProviderReq
 		return
 	}
 
-	requireProviderValue(resp, "token", client.AuthTokenEnvVar, &config.Token)
+	profile, diags := loadProviderProfile(config)
+	resp.Diagnostics.Append(diags...)
+
+	httpTransport, requestTimeout, diags := newHTTPTransport(config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	authToken, tokenSource, diags := resolveCredentials(config, profile, &http.Client{Transport: httpTransport, Timeout: requestTimeout})
+	resp.Diagnostics.Append(diags...)
+
+	if profile != nil {
+		if _, ok := os.LookupEnv(client.AccountIDEnvVar); !ok && config.AccountID.Value == "" {
+			config.AccountID.Value = profile.Account
+		}
+		if config.Host.Null && config.Region.Null && os.Getenv(client.HostEnvVar) == "" && os.Getenv(client.RegionEnvVar) == "" {
+			config.Host.Value = profile.Host
+			config.Region.Value = profile.Region
+		}
+	}
 	requireProviderValue(resp, "account_id", client.AccountIDEnvVar, &config.AccountID)
 	if resp.Diagnostics.HasError() {
 		return
@@ -106,7 +282,75 @@ func (p *provider) Configure(ctx context.Context, req tfsdk.ConfigureProviderReq
 		headers[k] = v
 	}
 
-	p.clientSet = newClientSet(config.Token.Value, config.AccountID.Value, headers)
+	if !config.Host.Null && !config.Region.Null {
+		resp.Diagnostics.AddAttributeError(path.Root("region"), "Conflicting provider values",
+			"Only one of \"host\" and \"region\" may be set in the provider block")
+	}
+	if !config.Region.Null {
+		if _, err := client.HostForRegion(config.Region.Value); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("region"), "Invalid region", err.Error())
+		}
+	}
+
+	endpoints := map[string]string{}
+	resp.Diagnostics.Append(config.Endpoints.ElementsAs(ctx, &endpoints, false)...)
+	for service, endpoint := range endpoints {
+		servicePath := path.Root("endpoints").AtMapKey(service)
+		if _, err := url.ParseRequestURI(endpoint); err != nil {
+			resp.Diagnostics.AddAttributeError(servicePath, "Invalid endpoint", err.Error())
+		}
+		if !isServiceName(service) {
+			resp.Diagnostics.AddAttributeWarning(servicePath, fmt.Sprintf("Unknown service %q", service),
+				fmt.Sprintf("The provider only calls the %s services, so this endpoint will never be used.",
+					strings.Join(serviceNames, ", ")))
+		}
+	}
+
+	var policy *client.PolicyDocument
+	if config.PolicyJSON.Value != "" {
+		if policy, err = parsePolicyJSON(config.PolicyJSON.Value); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("policy_json"), "Invalid policy document", err.Error())
+		}
+	}
+
+	lambdaFunctions, diags := parseLambdaFunctions(ctx, config.LambdaFunctions)
+	resp.Diagnostics.Append(diags...)
+
+	limiters, diags := newLimiters(config.RateLimit)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	clientConfig := client.Config{
+		AuthToken:       authToken,
+		TokenSource:     tokenSource,
+		AccountID:       config.AccountID.Value,
+		Host:            config.Host.Value,
+		Region:          config.Region.Value,
+		Endpoints:       endpoints,
+		Header:          headers,
+		UserID:          config.UserID.Value,
+		Policy:          policy,
+		UseLambda:       resolveUseLambda(config.UseLambda),
+		LambdaQualifier: config.LambdaQualifier.Value,
+		LambdaFunctions: lambdaFunctions,
+		Limiters:        limiters,
+		Transport:       httpTransport,
+		Timeout:         requestTimeout,
+	}
+	resp.Diagnostics.Append(applyRetryBlock(&clientConfig, config.Retry)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	clientSet, err := newClientSet(clientConfig)
+	if err != nil {
+		resp.Diagnostics.Append(clientSetErrorDiagnostic(err))
+		return
+	}
+
+	p.clientSet = clientSet
 	p.configured = true
 }
 
@@ -126,6 +370,67 @@ func requireProviderValue(resp *tfsdk.ConfigureProviderResponse, attribute, envV
 	value.Value = val
 }
 
+// clientSetErrorDiagnostic converts an error creating the provider's clients
+// into a diagnostic explaining how to resolve it.
+func clientSetErrorDiagnostic(err error) diag.Diagnostic {
+	switch {
+	case errors.Is(err, lambda.ErrMissingRegion):
+		return diag.NewAttributeErrorDiagnostic(path.Root("use_lambda"), "Missing AWS region",
+			fmt.Sprintf("Invoking lambda functions requires an AWS region. Set $AWS_REGION or a region in the "+
+				"AWS config file, or unset use_lambda and $%s.\n\n%s", client.UseLambdaEnvVar, err))
+	}
+	return diag.NewErrorDiagnostic("Unable to create PHC clients", err.Error())
+}
+
+// resolveUseLambda returns whether the provider's clients invoke lambda
+// functions. The use_lambda attribute takes precedence over
+// $LIFEOMIC_USE_LAMBDA, so that it can turn lambda mode off.
+func resolveUseLambda(value types.Bool) *bool {
+	useLambda := client.GetUseLambda()
+	if !value.Null && !value.Unknown {
+		useLambda = value.Value
+	}
+	return &useLambda
+}
+
+// parseLambdaFunctions parses the lambda_functions provider attribute into
+// lambda URIs keyed by service name.
+func parseLambdaFunctions(ctx context.Context, value types.Map) (map[string]lambda.URI, diag.Diagnostics) {
+	functions := map[string]string{}
+	diags := value.ElementsAs(ctx, &functions, false)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	uris := make(map[string]lambda.URI, len(functions))
+	for service, function := range functions {
+		servicePath := path.Root("lambda_functions").AtMapKey(service)
+
+		uri, err := lambda.ParseURI(function)
+		if err != nil {
+			diags.AddAttributeError(servicePath, "Invalid lambda function", err.Error())
+			continue
+		}
+
+		if !isServiceName(service) {
+			diags.AddAttributeWarning(servicePath, fmt.Sprintf("Unknown service %q", service),
+				fmt.Sprintf("The provider only calls the %s services, so this function will never be invoked.",
+					strings.Join(serviceNames, ", ")))
+		}
+		uris[service] = uri
+	}
+	return uris, diags
+}
+
+func isServiceName(service string) bool {
+	for _, s := range serviceNames {
+		if s == service {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *provider) GetResources(_ context.Context) (map[string]tfsdk.ResourceType, diag.Diagnostics) {
 	return map[string]tfsdk.ResourceType{
 		"lifeomic_policy":                        policyResourceType{},
@@ -134,7 +439,13 @@ func (p *provider) GetResources(_ context.Context) (map[string]tfsdk.ResourceTyp
 }
 
 func (p *provider) GetDataSources(_ context.Context) (map[string]tfsdk.DataSourceType, diag.Diagnostics) {
-	return map[string]tfsdk.DataSourceType{}, nil
+	return map[string]tfsdk.DataSourceType{
+		"lifeomic_account":         accountDataSourceType{},
+		"lifeomic_accounts":        accountsDataSourceType{},
+		"lifeomic_policies":        policiesDataSourceType{},
+		"lifeomic_policy_document": policyDocumentDataSourceType{},
+		"lifeomic_policy_test":     policyAssertionDataSourceType{},
+	}, nil
 }
 
 func errorConvertingProvider(v any) diag.Diagnostics {
@@ -1,14 +1,20 @@
 package provider
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"os"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
 	"github.com/lifeomic/terraform-provider-lifeomic/internal/client"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/lambda"
+	"github.com/stretchr/testify/assert"
 )
 
 var testAccProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
@@ -47,3 +53,144 @@ func randomResourceName(t *testing.T, chars int) string {
 	}
 	return fmt.Sprintf("tf-test-%X", randBytes)
 }
+
+func TestParseLambdaFunctions(t *testing.T) {
+	for _, fixture := range []struct {
+		name            string
+		functions       map[string]string
+		expectedValue   map[string]lambda.URI
+		expectedErr     string
+		expectedWarning string
+	}{
+		{
+			name:          "should parse functions",
+			functions:     map[string]string{"account-service": "lambda://account-service:staging", "marketplace-service": "marketplace"},
+			expectedValue: map[string]lambda.URI{"account-service": {Function: "account-service", Qualifier: "staging"}, "marketplace-service": {Function: "marketplace"}},
+		},
+		{
+			name:        "should reject invalid functions",
+			functions:   map[string]string{"account-service": "https://api.us.lifeomic.com"},
+			expectedErr: "Invalid lambda function",
+		},
+		{
+			name:            "should warn about unknown services",
+			functions:       map[string]string{"wellness-service": "wellness-service"},
+			expectedValue:   map[string]lambda.URI{"wellness-service": {Function: "wellness-service"}},
+			expectedWarning: `Unknown service "wellness-service"`,
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			elems := make(map[string]attr.Value, len(fixture.functions))
+			for service, function := range fixture.functions {
+				elems[service] = types.String{Value: function}
+			}
+
+			uris, diags := parseLambdaFunctions(context.Background(), types.Map{ElemType: types.StringType, Elems: elems})
+			if diags.HasError() {
+				if fixture.expectedErr == "" {
+					t.Errorf("unexpected error: %v", diags)
+					return
+				}
+				assert.Contains(t, diags.Errors()[0].Summary(), fixture.expectedErr)
+				return
+			} else if fixture.expectedErr != "" {
+				t.Errorf("expected error matching: %s; got nil", fixture.expectedErr)
+				return
+			}
+
+			if fixture.expectedWarning != "" {
+				warnings := diags.Warnings()
+				if assert.Len(t, warnings, 1) {
+					assert.Equal(t, fixture.expectedWarning, warnings[0].Summary())
+				}
+			} else {
+				assert.Empty(t, diags.Warnings())
+			}
+			assert.Equal(t, fixture.expectedValue, uris)
+		})
+	}
+}
+
+func TestClientSetErrorDiagnostic(t *testing.T) {
+	for _, fixture := range []struct {
+		name            string
+		err             error
+		expectedSummary string
+	}{
+		{
+			name:            "should explain missing regions",
+			err:             fmt.Errorf("failed to create lambda transport for account-service: %w", lambda.ErrMissingRegion),
+			expectedSummary: "Missing AWS region",
+		},
+		{
+			name:            "should report other errors",
+			err:             errors.New("failed to load AWS config"),
+			expectedSummary: "Unable to create PHC clients",
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			diagnostic := clientSetErrorDiagnostic(fixture.err)
+			assert.Equal(t, fixture.expectedSummary, diagnostic.Summary())
+			assert.Contains(t, diagnostic.Detail(), fixture.err.Error())
+		})
+	}
+}
+
+func TestResolveUseLambda(t *testing.T) {
+	for _, fixture := range []struct {
+		name          string
+		env           string
+		value         types.Bool
+		expectedValue bool
+	}{
+		{
+			name:          "should default to the environment variable",
+			env:           "1",
+			value:         types.Bool{Null: true},
+			expectedValue: true,
+		},
+		{
+			name:          "should let the attribute turn lambda mode off",
+			env:           "1",
+			value:         types.Bool{Value: false},
+			expectedValue: false,
+		},
+		{
+			name:          "should let the attribute turn lambda mode on",
+			env:           "",
+			value:         types.Bool{Value: true},
+			expectedValue: true,
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			t.Setenv(client.UseLambdaEnvVar, fixture.env)
+			assert.Equal(t, fixture.expectedValue, *resolveUseLambda(fixture.value))
+		})
+	}
+}
+
+func TestResolveUseLambda_transport(t *testing.T) {
+	t.Setenv(client.UseLambdaEnvVar, "1")
+
+	config := client.Config{
+		AuthToken:   "my-token",
+		ServiceName: "account-service",
+		UseLambda:   resolveUseLambda(types.Bool{Value: false}),
+	}
+	transport, err := client.NewAuthedTransport(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logging, ok := transport.Base.(*client.LoggingTransport)
+	if assert.True(t, ok) {
+		_, isLambda := logging.Base.(*lambda.RoundTripper)
+		assert.False(t, isLambda, "use_lambda = false should override $%s", client.UseLambdaEnvVar)
+	}
+
+	clientSet, err := newClientSet(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, clientSet.UseLambda)
+}
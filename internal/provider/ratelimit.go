@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/client"
+)
+
+// providerRateLimit represents one of the provider's rate_limit blocks.
+type providerRateLimit struct {
+	Service           types.String  `tfsdk:"service"`
+	RequestsPerSecond types.Float64 `tfsdk:"requests_per_second"`
+	Burst             types.Int64   `tfsdk:"burst"`
+	MaxInFlight       types.Int64   `tfsdk:"max_in_flight"`
+}
+
+func rateLimitBlock() tfsdk.Block {
+	return tfsdk.Block{
+		Description: "Limit the requests sent to a PHC service by all of the provider's resources and data " +
+			"sources, e.g. to stay below the service's rate limits when applying with a high " +
+			"`-parallelism`. Services without a `rate_limit` block aren't limited.",
+		NestingMode: tfsdk.BlockNestingModeList,
+		Attributes: map[string]tfsdk.Attribute{
+			"service": {
+				Type:     types.StringType,
+				Required: true,
+				Description: fmt.Sprintf("The PHC service (%s) whose requests to limit.",
+					"`"+strings.Join(serviceNames, "`, `")+"`"),
+			},
+			"requests_per_second": {
+				Type:        types.Float64Type,
+				Optional:    true,
+				Description: "The rate at which requests may be sent to the service. Unlimited if not set.",
+			},
+			"burst": {
+				Type:     types.Int64Type,
+				Optional: true,
+				Description: "The number of requests which may be sent at once before they're limited to " +
+					"`requests_per_second`. Defaults to `1`.",
+			},
+			"max_in_flight": {
+				Type:        types.Int64Type,
+				Optional:    true,
+				Description: "The number of requests which may be awaiting a response at once. Unlimited if not set.",
+			},
+		},
+	}
+}
+
+// newLimiters creates the Limiters of the provider's rate_limit blocks, keyed
+// by service name.
+func newLimiters(rateLimits []providerRateLimit) (map[string]*client.Limiter, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	limiters := make(map[string]*client.Limiter, len(rateLimits))
+
+	for i, rateLimit := range rateLimits {
+		blockPath := path.Root("rate_limit").AtListIndex(i)
+		service := rateLimit.Service.Value
+
+		if _, ok := limiters[service]; ok {
+			diags.AddAttributeError(blockPath.AtName("service"), "Duplicate rate limit",
+				fmt.Sprintf("Only one rate_limit block may be set for %q", service))
+			continue
+		}
+		if !isServiceName(service) {
+			diags.AddAttributeWarning(blockPath.AtName("service"), fmt.Sprintf("Unknown service %q", service),
+				fmt.Sprintf("The provider only calls the %s services, so this rate limit will never be used.",
+					strings.Join(serviceNames, ", ")))
+		}
+
+		if rateLimit.RequestsPerSecond.Value < 0 {
+			diags.AddAttributeError(blockPath.AtName("requests_per_second"), "Invalid rate limit",
+				"requests_per_second must not be negative")
+		}
+		if rateLimit.Burst.Value < 0 {
+			diags.AddAttributeError(blockPath.AtName("burst"), "Invalid rate limit", "burst must not be negative")
+		}
+		if rateLimit.MaxInFlight.Value < 0 {
+			diags.AddAttributeError(blockPath.AtName("max_in_flight"), "Invalid rate limit",
+				"max_in_flight must not be negative")
+		}
+
+		limiters[service] = client.NewLimiter(client.RateLimit{
+			RequestsPerSecond: rateLimit.RequestsPerSecond.Value,
+			Burst:             int(rateLimit.Burst.Value),
+			MaxInFlight:       int(rateLimit.MaxInFlight.Value),
+		})
+	}
+	return limiters, diags
+}
@@ -0,0 +1,40 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLimiters(t *testing.T) {
+	limiters, diags := newLimiters([]providerRateLimit{{
+		Service:           types.String{Value: "account-service"},
+		RequestsPerSecond: types.Float64{Value: 5},
+		Burst:             types.Int64{Null: true},
+		MaxInFlight:       types.Int64{Value: 4},
+	}})
+	assert.False(t, diags.HasError())
+	assert.Contains(t, limiters, "account-service")
+
+	_, diags = newLimiters([]providerRateLimit{
+		{Service: types.String{Value: "account-service"}, MaxInFlight: types.Int64{Value: 4}},
+		{Service: types.String{Value: "account-service"}, MaxInFlight: types.Int64{Value: 2}},
+	})
+	if assert.True(t, diags.HasError()) {
+		assert.Equal(t, "Duplicate rate limit", diags[0].Summary())
+	}
+
+	_, diags = newLimiters([]providerRateLimit{
+		{Service: types.String{Value: "marketplace-service"}, RequestsPerSecond: types.Float64{Value: -1}},
+	})
+	if assert.True(t, diags.HasError()) {
+		assert.Equal(t, "requests_per_second must not be negative", diags[0].Detail())
+	}
+
+	_, diags = newLimiters([]providerRateLimit{
+		{Service: types.String{Value: "unknown-service"}},
+	})
+	assert.False(t, diags.HasError())
+	assert.Equal(t, 1, diags.WarningsCount())
+}
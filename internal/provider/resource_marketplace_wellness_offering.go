@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"strings"
 	"time"
 
 	"github.com/blang/semver/v4"
@@ -18,22 +19,24 @@ import (
 	"github.com/lifeomic/terraform-provider-lifeomic/internal/gqlclient"
 )
 
-func retry[T any](fn func() (T, error), maxRetries int, delay time.Duration) (T, error) {
-	var err error
-	var res T
-	for attempts := 0; attempts < maxRetries; attempts++ {
-		res, err = fn()
-		if err == nil {
-			return res, err
-		}
-		time.Sleep(delay)
-	}
-	return res, err
+// wellnessOfferingPollPolicy is how the resource polls the marketplace while
+// an approved module is published.
+var wellnessOfferingPollPolicy = client.RetryPolicy{
+	MaxRetries:  9,
+	WaitTime:    500 * time.Millisecond,
+	MaxWaitTime: 5 * time.Second,
+}
+
+// isPollRetryable reports whether polling the marketplace should continue
+// after err. Errors the user has to resolve end polling early.
+func isPollRetryable(err error) bool {
+	return !gqlclient.IsForbidden(err) && !gqlclient.IsBadUserInput(err)
 }
 
 // wellnessOffering represents the state of marketplace_wellness_offering resource
 type wellnessOffering struct {
 	ID                  types.String `tfsdk:"id"`
+	AccountID           types.String `tfsdk:"account_id"`
 	ParentModuleId      types.String `tfsdk:"parent_module_id"`
 	Title               types.String `tfsdk:"title"`
 	Description         types.String `tfsdk:"description"`
@@ -84,6 +87,27 @@ func unmarshalPriceRange(ctx context.Context, input types.Object) (gqlclient.Pri
 	return priceRange, nil
 }
 
+// wellnessOfferingInputAttributes maps the fields of the marketplace's
+// module inputs to the attributes they're set from.
+var wellnessOfferingInputAttributes = map[string]string{
+	"id":                  "id",
+	"moduleId":            "id",
+	"parentModuleId":      "parent_module_id",
+	"title":               "title",
+	"description":         "description",
+	"provider":            "marketplace_provider",
+	"imageUrl":            "image_url",
+	"infoUrl":             "info_url",
+	"approximateUnitCost": "approximate_unit_cost",
+	"subsidyType":         "subsidy_type",
+	"appLink":             "app_link",
+	"installUrl":          "install_url",
+	"configurationSchema": "configuration_schema",
+	"iconUrl":             "icon_url",
+	"priceRange":          "price_range",
+	"isTestModule":        "is_test_module",
+}
+
 // wellnessOfferingResource implements tfsdk
 type wellnessOfferingResource struct {
 	clientSet *clientSet
@@ -101,6 +125,7 @@ func (wellnessOfferingResourceType) GetSchema(_ context.Context) (tfsdk.Schema,
 				Type:        types.StringType,
 				Description: "An optional id for the Wellness Offering",
 			},
+			"account_id": accountIDAttribute(),
 			"parent_module_id": {
 				Optional: true,
 				Type:     types.StringType,
@@ -218,6 +243,8 @@ func (wellnessOfferingResourceType) NewResource(_ context.Context, p tfsdk.Provi
 
 func (w wellnessOfferingResource) Create(ctx context.Context, req tfsdk.CreateResourceRequest, resp *tfsdk.CreateResourceResponse) {
 	tflog.Info(ctx, "Creating Wellness Offering Module")
+	ctx, span := startOperation(ctx, "lifeomic_marketplace_wellness_offering", "create")
+	defer func() { endOperation(span, resp.Diagnostics) }()
 
 	// Get plan values.
 	var plan wellnessOffering
@@ -226,6 +253,12 @@ func (w wellnessOfferingResource) Create(ctx context.Context, req tfsdk.CreateRe
 		return
 	}
 
+	clientSet, diags := w.clientSet.forResource(ctx, plan.AccountID)
+	if diags.HasError() {
+		resp.Diagnostics.Append(diags...)
+		return
+	}
+
 	// Map Terraform plan to *client.WellnessOffering object.
 	draftModuleInput, diags := plan.ToMarketplaceInputObject(ctx)
 	if diags.HasError() {
@@ -234,9 +267,9 @@ func (w wellnessOfferingResource) Create(ctx context.Context, req tfsdk.CreateRe
 	}
 
 	// Create the draft module.
-	draftModuleResp, err := w.clientSet.Marketplace.CreateDraftModule(ctx, draftModuleInput)
+	draftModuleResp, err := clientSet.Marketplace.CreateDraftModule(ctx, draftModuleInput)
 	if err != nil {
-		resp.Diagnostics.AddError("failed to create Wellness Draft Module", err.Error())
+		resp.Diagnostics.Append(graphQLErrorDiagnostics("failed to create Wellness Draft Module", err, wellnessOfferingInputAttributes)...)
 		return
 	}
 
@@ -267,16 +300,16 @@ func (w wellnessOfferingResource) Create(ctx context.Context, req tfsdk.CreateRe
 	}
 
 	// Set module source
-	setSourceResp, err := w.clientSet.Marketplace.SetWellnessOfferingDraftModuleSource(ctx, sourceInput)
+	setSourceResp, err := clientSet.Marketplace.SetWellnessOfferingDraftModuleSource(ctx, sourceInput)
 	if err != nil {
-		resp.Diagnostics.AddError("failed to set source of Wellness Draft Module", err.Error())
+		resp.Diagnostics.Append(graphQLErrorDiagnostics("failed to set source of Wellness Draft Module", err, wellnessOfferingInputAttributes)...)
 		return
 	}
 
 	tflog.Info(ctx, "Set module source", map[string]any{"moduleSource": setSourceResp.SetWellnessOfferingDraftModuleSource})
 
 	// Publish module
-	publishResp, err := w.clientSet.Marketplace.PublishModuleV3(ctx, gqlclient.PublishDraftModuleInputV3{
+	publishResp, err := clientSet.Marketplace.PublishModuleV3(ctx, gqlclient.PublishDraftModuleInputV3{
 		ModuleId: draftModuleResp.CreateDraftModule.Id,
 		Version: gqlclient.ModuleVersionInput{
 			Version: "1.0.0",
@@ -284,18 +317,20 @@ func (w wellnessOfferingResource) Create(ctx context.Context, req tfsdk.CreateRe
 		IsTestModule: plan.IsTestModule.Value,
 	})
 	if err != nil {
-		resp.Diagnostics.AddError("failed to publish Wellness Offering Module", err.Error())
+		resp.Diagnostics.Append(graphQLErrorDiagnostics("failed to publish Wellness Offering Module", err, wellnessOfferingInputAttributes)...)
 		return
 	}
 
 	tflog.Info(ctx, "Published module", map[string]any{"module": publishResp.PublishDraftModuleV3})
 
-	w.handleApproval(ctx, plan, publishResp.PublishDraftModuleV3.Id, &resp.State, &resp.Diagnostics)
+	w.handleApproval(ctx, clientSet, plan, publishResp.PublishDraftModuleV3.Id, &resp.State, &resp.Diagnostics)
 
 }
 
 func (w wellnessOfferingResource) Read(ctx context.Context, req tfsdk.ReadResourceRequest, resp *tfsdk.ReadResourceResponse) {
 	tflog.Info(ctx, "Reading Wellness Offering resource")
+	ctx, span := startOperation(ctx, "lifeomic_marketplace_wellness_offering", "read")
+	defer func() { endOperation(span, resp.Diagnostics) }()
 
 	// Get current state.
 	var state wellnessOffering
@@ -304,9 +339,21 @@ func (w wellnessOfferingResource) Read(ctx context.Context, req tfsdk.ReadResour
 		return
 	}
 
-	offering, err := w.clientSet.Marketplace.GetWellnessOfferingModule(ctx, state.ID.Value)
-	if err != nil {
-		resp.Diagnostics.AddError("failed to get wellness offering module", err.Error())
+	clientSet, diags := w.clientSet.forResource(ctx, state.AccountID)
+	if diags.HasError() {
+		resp.Diagnostics.Append(diags...)
+		return
+	}
+
+	offering, err := clientSet.Marketplace.GetWellnessOfferingModule(ctx, state.ID.Value)
+	if gqlclient.IsNotFound(err) {
+		// The module was deleted outside of Terraform, so it needs to be
+		// created again.
+		tflog.Warn(ctx, "Wellness Offering Module not found, removing it from state", map[string]any{"id": state.ID.Value})
+		resp.State.RemoveResource(ctx)
+		return
+	} else if err != nil {
+		resp.Diagnostics.Append(graphQLErrorDiagnostics("failed to get wellness offering module", err, wellnessOfferingInputAttributes)...)
 		return
 	}
 
@@ -316,6 +363,8 @@ func (w wellnessOfferingResource) Read(ctx context.Context, req tfsdk.ReadResour
 
 func (w wellnessOfferingResource) Update(ctx context.Context, req tfsdk.UpdateResourceRequest, resp *tfsdk.UpdateResourceResponse) {
 	tflog.Info(ctx, "Updating Wellness Offering Module")
+	ctx, span := startOperation(ctx, "lifeomic_marketplace_wellness_offering", "update")
+	defer func() { endOperation(span, resp.Diagnostics) }()
 
 	// Get plan values.
 	var plan wellnessOffering
@@ -331,6 +380,12 @@ func (w wellnessOfferingResource) Update(ctx context.Context, req tfsdk.UpdateRe
 		return
 	}
 
+	clientSet, diags := w.clientSet.forResource(ctx, state.AccountID)
+	if diags.HasError() {
+		resp.Diagnostics.Append(diags...)
+		return
+	}
+
 	currentVersion, err := semver.Parse(state.Version.Value)
 	if err != nil {
 		resp.Diagnostics.AddError("unable to parse module version in state", err.Error())
@@ -351,9 +406,9 @@ func (w wellnessOfferingResource) Update(ctx context.Context, req tfsdk.UpdateRe
 	draftModuleInput.ParentModuleId = state.ID.Value
 
 	// Create the draft module.
-	draftModuleResp, err := w.clientSet.Marketplace.CreateDraftModule(ctx, draftModuleInput)
+	draftModuleResp, err := clientSet.Marketplace.CreateDraftModule(ctx, draftModuleInput)
 	if err != nil {
-		resp.Diagnostics.AddError("failed to create Wellness Draft Module", err.Error())
+		resp.Diagnostics.Append(graphQLErrorDiagnostics("failed to create Wellness Draft Module", err, wellnessOfferingInputAttributes)...)
 		return
 	}
 	tflog.Info(ctx, "Created new DraftModule", map[string]any{"draftModule": draftModuleResp.CreateDraftModule})
@@ -382,15 +437,15 @@ func (w wellnessOfferingResource) Update(ctx context.Context, req tfsdk.UpdateRe
 		sourceInput.SourceInfo.PriceRange = priceRange
 	}
 
-	setSourceResp, err := w.clientSet.Marketplace.SetWellnessOfferingDraftModuleSource(ctx, sourceInput)
+	setSourceResp, err := clientSet.Marketplace.SetWellnessOfferingDraftModuleSource(ctx, sourceInput)
 	if err != nil {
-		resp.Diagnostics.AddError("failed to set source of Wellness Offering draft module", err.Error())
+		resp.Diagnostics.Append(graphQLErrorDiagnostics("failed to set source of Wellness Offering draft module", err, wellnessOfferingInputAttributes)...)
 		return
 	}
 
 	tflog.Info(ctx, "Updated existing Wellness Offering Module source", map[string]any{"moduleSource": setSourceResp.SetWellnessOfferingDraftModuleSource})
 	// Publish module
-	publishResp, err := w.clientSet.Marketplace.PublishModuleV3(ctx, gqlclient.PublishDraftModuleInputV3{
+	publishResp, err := clientSet.Marketplace.PublishModuleV3(ctx, gqlclient.PublishDraftModuleInputV3{
 		ModuleId: draftModuleResp.CreateDraftModule.Id,
 		Version: gqlclient.ModuleVersionInput{
 			Version: currentVersion.String(),
@@ -398,15 +453,17 @@ func (w wellnessOfferingResource) Update(ctx context.Context, req tfsdk.UpdateRe
 		IsTestModule: plan.IsTestModule.Value,
 	})
 	if err != nil {
-		resp.Diagnostics.AddError("failed to publish Wellness Offering Module", err.Error())
+		resp.Diagnostics.Append(graphQLErrorDiagnostics("failed to publish Wellness Offering Module", err, wellnessOfferingInputAttributes)...)
 		return
 	}
 
-	w.handleApproval(ctx, plan, publishResp.PublishDraftModuleV3.Id, &resp.State, &resp.Diagnostics)
+	w.handleApproval(ctx, clientSet, plan, publishResp.PublishDraftModuleV3.Id, &resp.State, &resp.Diagnostics)
 }
 
 func (w wellnessOfferingResource) Delete(ctx context.Context, req tfsdk.DeleteResourceRequest, resp *tfsdk.DeleteResourceResponse) {
 	tflog.Info(ctx, "Deleting Wellness Offering Module")
+	ctx, span := startOperation(ctx, "lifeomic_marketplace_wellness_offering", "delete")
+	defer func() { endOperation(span, resp.Diagnostics) }()
 
 	// Get current state.
 	var state wellnessOffering
@@ -415,30 +472,47 @@ func (w wellnessOfferingResource) Delete(ctx context.Context, req tfsdk.DeleteRe
 		return
 	}
 
-	deleteModuleResp, err := w.clientSet.Marketplace.DeleteModule(ctx, gqlclient.DeleteModuleInput{
+	clientSet, diags := w.clientSet.forResource(ctx, state.AccountID)
+	if diags.HasError() {
+		resp.Diagnostics.Append(diags...)
+		return
+	}
+
+	deleteModuleResp, err := clientSet.Marketplace.DeleteModule(ctx, gqlclient.DeleteModuleInput{
 		ModuleId: state.ID.Value,
 	})
-	if err != nil {
-		resp.Diagnostics.AddError("failed to delete Wellness Offering Module", err.Error())
+	if gqlclient.IsNotFound(err) {
+		tflog.Warn(ctx, "Wellness Offering Module was already deleted", map[string]any{"id": state.ID.Value})
+	} else if err != nil {
+		resp.Diagnostics.Append(graphQLErrorDiagnostics("failed to delete Wellness Offering Module", err, wellnessOfferingInputAttributes)...)
 		return
+	} else {
+		tflog.Info(ctx, "deleted Wellness Offering", map[string]any{"deleteResp": deleteModuleResp.DeleteModule})
 	}
 
-	tflog.Info(ctx, "deleted Wellness Offering", map[string]any{"deleteResp": deleteModuleResp.DeleteModule})
-
 	resp.State.RemoveResource(ctx)
 	tflog.Info(ctx, "Deleted Wellness Offering", map[string]any{"Name": state.Title})
 }
 
+// ImportState imports a module by its ID, or by <account_id>/<id> for modules
+// in accounts other than the provider's.
 func (w wellnessOfferingResource) ImportState(ctx context.Context, req tfsdk.ImportResourceStateRequest, resp *tfsdk.ImportResourceStateResponse) {
-	tfsdk.ResourceImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	accountID, id, ok := strings.Cut(req.ID, "/")
+	if !ok {
+		tfsdk.ResourceImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("account_id"), accountID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id)...)
 }
 
-func (w wellnessOfferingResource) handleApproval(ctx context.Context, plan wellnessOffering, moduleId string, state *tfsdk.State, diags *diag.Diagnostics) {
+func (w wellnessOfferingResource) handleApproval(ctx context.Context, clientSet *clientSet, plan wellnessOffering, moduleId string, state *tfsdk.State, diags *diag.Diagnostics) {
 	// if it's a test module, it's automatically approved so we can set state and exit
 	if plan.IsTestModule.Value {
-		offering, err := w.clientSet.Marketplace.GetWellnessOfferingModule(ctx, moduleId)
+		offering, err := clientSet.Marketplace.GetWellnessOfferingModule(ctx, moduleId)
 		if err != nil {
-			diags.AddError("failed to get published Wellness Offering Module", err.Error())
+			diags.Append(graphQLErrorDiagnostics("failed to get published Wellness Offering Module", err, wellnessOfferingInputAttributes)...)
 			return
 		}
 		tflog.Info(ctx, "Got Wellness Offering Module", map[string]any{"module": offering.MyModule.WellnessOfferingModule})
@@ -446,14 +520,14 @@ func (w wellnessOfferingResource) handleApproval(ctx context.Context, plan welln
 		return
 	}
 
-	getDraftModuleResp, err := w.clientSet.Marketplace.GetDraftWellnessOfferingModule(ctx, moduleId)
+	getDraftModuleResp, err := clientSet.Marketplace.GetDraftWellnessOfferingModule(ctx, moduleId)
 	if err != nil {
-		diags.AddError("failed to get draft Wellness Offering Module", err.Error())
+		diags.Append(graphQLErrorDiagnostics("failed to get draft Wellness Offering Module", err, wellnessOfferingInputAttributes)...)
 		return
 	}
 	tflog.Info(ctx, "Got draft Wellness Offering Module", map[string]any{"module": getDraftModuleResp.DraftModule})
 
-	if !client.GetUseLambda() {
+	if !clientSet.UseLambda {
 		tflog.Warn(ctx, "unable to automatically approve module. Module will be left in ready to review state and requires manual approval.")
 		nonDraft, err := draftModuleToNonDraft(getDraftModuleResp.DraftModule.DraftWellnessOfferingModule)
 		if err != nil {
@@ -467,34 +541,34 @@ func (w wellnessOfferingResource) handleApproval(ctx context.Context, plan welln
 	// If we're using lambda we automatically attempt to publish a review for the module
 	tflog.Info(ctx, "using lambda detected. Attempting to automatically approve the module")
 
-	assignModuleForReview := func() (*gqlclient.AssignModuleReviewToSelfResponse, error) {
-		return w.clientSet.Marketplace.AssignModuleReviewToSelf(ctx, moduleId)
+	assignModuleForReview := func(ctx context.Context) (*gqlclient.AssignModuleReviewToSelfResponse, error) {
+		return clientSet.Marketplace.AssignModuleReviewToSelf(ctx, moduleId)
 	}
 
-	assignModuleResp, err := retry(assignModuleForReview, 10, time.Second*1)
+	assignModuleResp, err := client.Retry(ctx, wellnessOfferingPollPolicy, isPollRetryable, assignModuleForReview)
 	if err != nil {
-		diags.AddError("failed to assign module for review", err.Error())
+		diags.Append(graphQLErrorDiagnostics("failed to assign module for review", err, wellnessOfferingInputAttributes)...)
 		return
 	}
 	tflog.Info(ctx, "Assigned module to self for review", map[string]any{"assigned": assignModuleResp.AssignDraftModuleForReview})
 
-	approveResp, err := w.clientSet.Marketplace.ApproveModule(ctx, gqlclient.ApproveModulePublishInput{
+	approveResp, err := clientSet.Marketplace.ApproveModule(ctx, gqlclient.ApproveModulePublishInput{
 		ModuleId: moduleId,
 		Notes:    "Automatically approved by terraform provider",
 	})
 	if err != nil {
-		diags.AddError("failed to approve module", err.Error())
+		diags.Append(graphQLErrorDiagnostics("failed to approve module", err, wellnessOfferingInputAttributes)...)
 		return
 	}
 	tflog.Info(ctx, "Approved module", map[string]any{"approval": approveResp.ApproveModulePublish})
 
-	getApprovedModule := func() (*gqlclient.GetWellnessOfferingModuleResponse, error) {
-		return w.clientSet.Marketplace.GetWellnessOfferingModule(ctx, moduleId)
+	getApprovedModule := func(ctx context.Context) (*gqlclient.GetWellnessOfferingModuleResponse, error) {
+		return clientSet.Marketplace.GetWellnessOfferingModule(ctx, moduleId)
 	}
 
-	offering, err := retry(getApprovedModule, 10, time.Second*1)
+	offering, err := client.Retry(ctx, wellnessOfferingPollPolicy, isPollRetryable, getApprovedModule)
 	if err != nil {
-		diags.AddError("failed to get published and approved Wellness Offering Module", err.Error())
+		diags.Append(graphQLErrorDiagnostics("failed to get published and approved Wellness Offering Module", err, wellnessOfferingInputAttributes)...)
 		return
 	}
 	tflog.Info(ctx, "Got Wellness Offering Module", map[string]any{"module": offering.MyModule.WellnessOfferingModule})
@@ -525,6 +599,7 @@ func setWellnessOfferingState(ctx context.Context, config *wellnessOffering, sta
 	}
 
 	diags.Append(state.Set(ctx, wellnessOffering{
+		AccountID:      config.AccountID,
 		ParentModuleId: config.ParentModuleId,
 		IsEnabled:      config.IsEnabled,
 		IsTestModule:   config.IsTestModule,
@@ -9,27 +9,34 @@ import (
 	"github.com/hashicorp/go-uuid"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
-	"github.com/lifeomic/terraform-provider-lifeomic/internal/common"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/client"
 )
 
 const (
-	defaultHeadersf = "{\"LifeOmic-Policy\":\"{\\\"rules\\\":{\\\"publishContent\\\":true, \\\"lifeomicMarketplaceAdmin\\\": true}}\",\"LifeOmic-User\":\"%s\",\"LifeOmic-Account\":\"%s\"}"
-	defaultDesc     = "A fake marketplace wellness offering"
+	testCallerPolicy = `{"rules": {"publishContent": true, "lifeomicMarketplaceAdmin": true}}`
+	defaultDesc      = "A fake marketplace wellness offering"
 )
 
 func skipNoLambda(t *testing.T) {
-	if os.Getenv(useLambdaEnvVar) == "" {
-		t.Skipf("skipping test. Set %s env var in order to run this test", useLambdaEnvVar)
+	if os.Getenv(client.UseLambdaEnvVar) == "" {
+		t.Skipf("skipping test. Set %s env var in order to run this test", client.UseLambdaEnvVar)
 	}
 }
 
-func getHeaders(t *testing.T) string {
+// setTestCaller configures the provider and test clients to act as a
+// marketplace admin.
+func setTestCaller(t *testing.T) {
 	t.Helper()
 
-	username := "tf-provider"
-	account := "lifeomic"
+	t.Setenv(client.AccountIDEnvVar, "lifeomic")
+	t.Setenv(client.UserIDEnvVar, "tf-provider")
+	t.Setenv(client.PolicyEnvVar, testCallerPolicy)
+}
 
-	return fmt.Sprintf(defaultHeadersf, username, account)
+// newTestClientSet creates a clientSet for checking resources, configured by
+// the same environment variables as the provider.
+func newTestClientSet() (*clientSet, error) {
+	return newClientSet(client.Config{AccountID: os.Getenv(client.AccountIDEnvVar)})
 }
 
 var testWellnessOfferingResName = "lifeomic_marketplace_wellness_offering.test"
@@ -37,11 +44,7 @@ var testWellnessOfferingResName = "lifeomic_marketplace_wellness_offering.test"
 func TestAccMarketplaceWellnessOffering_basic(t *testing.T) {
 	skipNoLambda(t)
 	id, _ := uuid.GenerateUUID()
-	t.Setenv(common.HeadersEnvVar, getHeaders(t))
-	header, err := common.HeaderFromEnv()
-	if err != nil {
-		t.Fatalf("error getting required headers %v", err)
-	}
+	setTestCaller(t)
 
 	resource.Test(t, resource.TestCase{
 		ProtoV6ProviderFactories: testAccProviderFactories,
@@ -49,7 +52,7 @@ func TestAccMarketplaceWellnessOffering_basic(t *testing.T) {
 		Steps: []resource.TestStep{
 			{
 				Config: testAccOffering_basic(id, true, defaultDesc),
-				Check: resource.ComposeAggregateTestCheckFunc(testCheckPublishedModule(t, id, header),
+				Check: resource.ComposeAggregateTestCheckFunc(testCheckPublishedModule(t, id),
 					resource.TestCheckResourceAttr(testWellnessOfferingResName, "is_test_module", "true"),
 					resource.TestCheckResourceAttr(testWellnessOfferingResName, "is_approved", "true"),
 				),
@@ -61,11 +64,7 @@ func TestAccMarketplaceWellnessOffering_basic(t *testing.T) {
 func TestAccMarketplaceWellnessOffering_basicWithAppLink(t *testing.T) {
 	skipNoLambda(t)
 	id, _ := uuid.GenerateUUID()
-	t.Setenv(common.HeadersEnvVar, getHeaders(t))
-	header, err := common.HeaderFromEnv()
-	if err != nil {
-		t.Fatalf("error getting required headers %v", err)
-	}
+	setTestCaller(t)
 
 	resource.Test(t, resource.TestCase{
 		ProtoV6ProviderFactories: testAccProviderFactories,
@@ -73,7 +72,7 @@ func TestAccMarketplaceWellnessOffering_basicWithAppLink(t *testing.T) {
 		Steps: []resource.TestStep{
 			{
 				Config: testAccOffering_withAppLink(id, true, defaultDesc),
-				Check: resource.ComposeAggregateTestCheckFunc(testCheckPublishedModule(t, id, header),
+				Check: resource.ComposeAggregateTestCheckFunc(testCheckPublishedModule(t, id),
 					resource.TestCheckResourceAttr(testWellnessOfferingResName, "is_test_module", "true"),
 					resource.TestCheckResourceAttr(testWellnessOfferingResName, "is_approved", "true"),
 					resource.TestCheckResourceAttr(testWellnessOfferingResName, "app_link", "https://example.com"),
@@ -87,12 +86,8 @@ func TestAccMarketplaceWellnessOffering_basicUpdate(t *testing.T) {
 	skipNoLambda(t)
 	id, _ := uuid.GenerateUUID()
 
-	t.Setenv(common.HeadersEnvVar, getHeaders(t))
-	t.Setenv(useLambdaEnvVar, "1")
-	header, err := common.HeaderFromEnv()
-	if err != nil {
-		t.Fatalf("error getting required headers %v", err)
-	}
+	setTestCaller(t)
+	t.Setenv(client.UseLambdaEnvVar, "1")
 
 	resource.Test(t, resource.TestCase{
 		ProtoV6ProviderFactories: testAccProviderFactories,
@@ -100,7 +95,7 @@ func TestAccMarketplaceWellnessOffering_basicUpdate(t *testing.T) {
 		Steps: []resource.TestStep{
 			{
 				Config: testAccOffering_basic(id, true, defaultDesc),
-				Check: resource.ComposeAggregateTestCheckFunc(testCheckPublishedModule(t, id, header),
+				Check: resource.ComposeAggregateTestCheckFunc(testCheckPublishedModule(t, id),
 					resource.TestCheckResourceAttr(testWellnessOfferingResName, "is_approved", "true"),
 					resource.TestCheckResourceAttr(testWellnessOfferingResName, "is_test_module", "true"),
 					resource.TestCheckResourceAttr(testWellnessOfferingResName, "version", "1.0.0")),
@@ -109,7 +104,11 @@ func TestAccMarketplaceWellnessOffering_basicUpdate(t *testing.T) {
 				Config: testAccOffering_basic(id, true, "a new description"),
 				Check: resource.ComposeAggregateTestCheckFunc(
 					func(s *terraform.State) error {
-						client := newClientSet("", "", header).Marketplace
+						clientSet, err := newTestClientSet()
+						if err != nil {
+							return err
+						}
+						client := clientSet.Marketplace
 
 						module, err := client.GetPublishedModule(context.Background(), id, "")
 						if err != nil {
@@ -135,12 +134,8 @@ func TestAccMarketplaceWellnessOffering_automaticApproval(t *testing.T) {
 	skipNoLambda(t)
 	id, _ := uuid.GenerateUUID()
 
-	t.Setenv(common.HeadersEnvVar, getHeaders(t))
-	t.Setenv(useLambdaEnvVar, "1")
-	header, err := common.HeaderFromEnv()
-	if err != nil {
-		t.Fatalf("error getting required headers %v", err)
-	}
+	setTestCaller(t)
+	t.Setenv(client.UseLambdaEnvVar, "1")
 
 	resource.Test(t, resource.TestCase{
 		ProtoV6ProviderFactories: testAccProviderFactories,
@@ -150,9 +145,13 @@ func TestAccMarketplaceWellnessOffering_automaticApproval(t *testing.T) {
 				Config: testAccOffering_basic(id, false, defaultDesc),
 				Check: resource.ComposeAggregateTestCheckFunc(
 					func(s *terraform.State) error {
-						client := newClientSet("", "", header).Marketplace
+						clientSet, err := newTestClientSet()
+						if err != nil {
+							return err
+						}
+						client := clientSet.Marketplace
 
-						_, err := client.GetPublishedModule(context.Background(), id, "")
+						_, err = client.GetPublishedModule(context.Background(), id, "")
 						if err != nil {
 							return err
 						}
@@ -170,12 +169,8 @@ func TestAccMarketplaceWellnessOffering_automaticApprovalWithUpdates(t *testing.
 	skipNoLambda(t)
 	id, _ := uuid.GenerateUUID()
 
-	t.Setenv(common.HeadersEnvVar, getHeaders(t))
-	t.Setenv(useLambdaEnvVar, "1")
-	header, err := common.HeaderFromEnv()
-	if err != nil {
-		t.Fatalf("error getting required headers %v", err)
-	}
+	setTestCaller(t)
+	t.Setenv(client.UseLambdaEnvVar, "1")
 
 	resource.Test(t, resource.TestCase{
 		ProtoV6ProviderFactories: testAccProviderFactories,
@@ -183,7 +178,7 @@ func TestAccMarketplaceWellnessOffering_automaticApprovalWithUpdates(t *testing.
 		Steps: []resource.TestStep{
 			{
 				Config: testAccOffering_basic(id, false, defaultDesc),
-				Check: resource.ComposeAggregateTestCheckFunc(testCheckPublishedModule(t, id, header),
+				Check: resource.ComposeAggregateTestCheckFunc(testCheckPublishedModule(t, id),
 					resource.TestCheckResourceAttr(testWellnessOfferingResName, "is_approved", "true"),
 					resource.TestCheckResourceAttr(testWellnessOfferingResName, "version", "1.0.0")),
 			},
@@ -191,7 +186,11 @@ func TestAccMarketplaceWellnessOffering_automaticApprovalWithUpdates(t *testing.
 				Config: testAccOffering_basic(id, false, "a really fake module"),
 				Check: resource.ComposeAggregateTestCheckFunc(
 					func(s *terraform.State) error {
-						client := newClientSet("", "", header).Marketplace
+						clientSet, err := newTestClientSet()
+						if err != nil {
+							return err
+						}
+						client := clientSet.Marketplace
 
 						module, err := client.GetPublishedModule(context.Background(), id, "")
 						if err != nil {
@@ -216,12 +215,8 @@ func TestAccMarketplaceWellnessOffering_priceRange(t *testing.T) {
 	skipNoLambda(t)
 	id, _ := uuid.GenerateUUID()
 
-	t.Setenv(common.HeadersEnvVar, getHeaders(t))
-	t.Setenv(useLambdaEnvVar, "1")
-	header, err := common.HeaderFromEnv()
-	if err != nil {
-		t.Fatalf("error getting required headers %v", err)
-	}
+	setTestCaller(t)
+	t.Setenv(client.UseLambdaEnvVar, "1")
 
 	resource.Test(t, resource.TestCase{
 		ProtoV6ProviderFactories: testAccProviderFactories,
@@ -229,7 +224,7 @@ func TestAccMarketplaceWellnessOffering_priceRange(t *testing.T) {
 		Steps: []resource.TestStep{
 			{
 				Config: testAccOffering_withPriceRange(id, false, 5_000, 10_000),
-				Check: resource.ComposeAggregateTestCheckFunc(testCheckPublishedModule(t, id, header),
+				Check: resource.ComposeAggregateTestCheckFunc(testCheckPublishedModule(t, id),
 					resource.TestCheckResourceAttr(testWellnessOfferingResName, "is_approved", "true"),
 					resource.TestCheckResourceAttr(testWellnessOfferingResName, "version", "1.0.0"),
 					resource.TestCheckResourceAttr(testWellnessOfferingResName, "price_range.low", "5000"),
@@ -239,7 +234,11 @@ func TestAccMarketplaceWellnessOffering_priceRange(t *testing.T) {
 				Config: testAccOffering_withPriceRange(id, false, 1_000, 10_000),
 				Check: resource.ComposeAggregateTestCheckFunc(
 					func(s *terraform.State) error {
-						client := newClientSet("", "", header).Marketplace
+						clientSet, err := newTestClientSet()
+						if err != nil {
+							return err
+						}
+						client := clientSet.Marketplace
 
 						module, err := client.GetPublishedModule(context.Background(), id, "")
 						if err != nil {
@@ -265,12 +264,8 @@ func TestAccMarketplaceWellnessOffering_import(t *testing.T) {
 	skipNoLambda(t)
 	id, _ := uuid.GenerateUUID()
 
-	t.Setenv(common.HeadersEnvVar, getHeaders(t))
-	t.Setenv(useLambdaEnvVar, "1")
-	header, err := common.HeaderFromEnv()
-	if err != nil {
-		t.Fatalf("error getting required headers %v", err)
-	}
+	setTestCaller(t)
+	t.Setenv(client.UseLambdaEnvVar, "1")
 
 	resource.Test(t, resource.TestCase{
 		ProtoV6ProviderFactories: testAccProviderFactories,
@@ -278,7 +273,7 @@ func TestAccMarketplaceWellnessOffering_import(t *testing.T) {
 		Steps: []resource.TestStep{
 			{
 				Config: testAccOffering_basic(id, false, defaultDesc),
-				Check: resource.ComposeAggregateTestCheckFunc(testCheckPublishedModule(t, id, header),
+				Check: resource.ComposeAggregateTestCheckFunc(testCheckPublishedModule(t, id),
 					resource.TestCheckResourceAttr(testWellnessOfferingResName, "is_approved", "true"),
 					resource.TestCheckResourceAttr(testWellnessOfferingResName, "version", "1.0.0")),
 			},
@@ -287,7 +282,7 @@ func TestAccMarketplaceWellnessOffering_import(t *testing.T) {
 				ImportStateId: id,
 				ResourceName:  testWellnessOfferingResName,
 				Config:        testAccOffering_basic(id, false, defaultDesc),
-				Check: resource.ComposeAggregateTestCheckFunc(testCheckPublishedModule(t, id, header),
+				Check: resource.ComposeAggregateTestCheckFunc(testCheckPublishedModule(t, id),
 					resource.TestCheckResourceAttr(testWellnessOfferingResName, "is_approved", "true"),
 					resource.TestCheckResourceAttr(testWellnessOfferingResName, "version", "1.0.0")),
 			},
@@ -301,12 +296,8 @@ func TestAccMarketplaceWellnessOffering_import(t *testing.T) {
 // func TestAccMarketplaceWellnessOffering_noAutoApproval(t *testing.T) {
 // 	id, _ := uuid.GenerateUUID()
 
-// 	t.Setenv(common.HeadersEnvVar, getHeaders(t))
-// 	t.Setenv(useLambdaEnvVar, "")
-// 	header, err := common.HeaderFromEnv()
-// 	if err != nil {
-// 		t.Fatalf("error getting required headers %v", err)
-// 	}
+// 	setTestCaller(t)
+// 	t.Setenv(client.UseLambdaEnvVar, "")
 
 // 	resource.Test(t, resource.TestCase{
 // 		ProtoV6ProviderFactories: testAccProviderFactories,
@@ -316,7 +307,7 @@ func TestAccMarketplaceWellnessOffering_import(t *testing.T) {
 // 				Config: testAccOffering_basic(id, false),
 // 				Check: resource.ComposeAggregateTestCheckFunc(
 // 					func(s *terraform.State) error {
-// 						client := newClientSet("", "", header).Marketplace
+// 						client := newTestClientSet().Marketplace
 
 // 						_, err := client.GetDraftWellnessOfferingModule(context.Background(), id)
 // 						if err != nil {
@@ -398,13 +389,17 @@ func testAccOffering_withPriceRange(id string, isTest bool, low, high int) strin
 	}`, id, isTest, low, high)
 }
 
-func testCheckPublishedModule(t *testing.T, id string, header map[string]string) func(s *terraform.State) error {
+func testCheckPublishedModule(t *testing.T, id string) func(s *terraform.State) error {
 	t.Helper()
 	return func(s *terraform.State) error {
 
-		client := newClientSet("", "", header).Marketplace
+		clientSet, err := newTestClientSet()
+		if err != nil {
+			return err
+		}
+		client := clientSet.Marketplace
 
-		_, err := client.GetPublishedModule(context.Background(), id, "")
+		_, err = client.GetPublishedModule(context.Background(), id, "")
 		if err != nil {
 			return err
 		}
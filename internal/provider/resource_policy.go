@@ -2,8 +2,11 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
@@ -25,9 +28,11 @@ const (
 
 // policy represents the state of a lifeomic_policy resource.
 type policy struct {
-	ID   types.String `tfsdk:"id"`
-	Name types.String `tfsdk:"name"`
-	Rule []policyRule `tfsdk:"rule"`
+	ID         types.String `tfsdk:"id"`
+	AccountID  types.String `tfsdk:"account_id"`
+	Name       types.String `tfsdk:"name"`
+	PolicyJSON types.String `tfsdk:"policy_json"`
+	Rule       []policyRule `tfsdk:"rule"`
 }
 
 // policyRule represents the state of a lifeomic_policy resource's rule block.
@@ -77,6 +82,39 @@ func (m *concreteValuePlanModifier) Modify(ctx context.Context, req tfsdk.Modify
 	res.AttributePlan = value
 }
 
+// policyJSONPlanModifier is a tfsdk.AttributePlanModifier for the
+// lifeomic_policy.policy_json attribute. It plans the value in the state if
+// the configured document only differs from it in key order or whitespace,
+// so that reformatting the document doesn't update the policy. Policies
+// defined by rule blocks plan a null value rather than an unknown one.
+type policyJSONPlanModifier struct {
+	terraformDescriptionNoop
+}
+
+func (m *policyJSONPlanModifier) Modify(ctx context.Context, req tfsdk.ModifyAttributePlanRequest, resp *tfsdk.ModifyAttributePlanResponse) {
+	var config, state types.String
+	resp.Diagnostics.Append(tfsdk.ValueAs(ctx, req.AttributeConfig, &config)...)
+	resp.Diagnostics.Append(tfsdk.ValueAs(ctx, req.AttributeState, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if config.Null {
+		resp.AttributePlan = types.String{Null: true}
+		return
+	}
+	if config.Unknown || state.Null || state.Unknown {
+		return
+	}
+
+	configured, err := normalizePolicyJSON(config.Value)
+	if err != nil {
+		return
+	}
+	if current, err := normalizePolicyJSON(state.Value); err == nil && configured == current {
+		resp.AttributePlan = req.AttributeState
+	}
+}
+
 func policyIDPlanModifier() tfsdk.AttributePlanModifier {
 	return &concreteValuePlanModifier{
 		Getter: func(ctx context.Context, req tfsdk.ModifyAttributePlanRequest) (val attr.Value, diags diag.Diagnostics) {
@@ -108,67 +146,91 @@ func (policyResourceType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagn
 					policyIDPlanModifier(),
 				},
 			},
+			"account_id": accountIDAttribute(),
+			"policy_json": {
+				Type:     types.StringType,
+				Optional: true,
+				// Computed allows policyJSONPlanModifier to plan the state's
+				// value instead of the configured one.
+				Computed: true,
+				Description: fmt.Sprintf("A JSON encoded [ABAC policy document](%s) (e.g. `{\"rules\": {\"readData\": true}}`). "+
+					"Conflicts with `rule`. Exactly one of `policy_json` and `rule` should be set.", policyRuleDocsURL),
+				Validators: []tfsdk.AttributeValidator{
+					&policyJSONValidator{},
+				},
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					&policyJSONPlanModifier{},
+				},
+			},
+		},
+		Blocks: map[string]tfsdk.Block{
+			"rule": policyRuleBlock(),
+		},
+	}, nil
+}
+
+// policyRuleBlock returns the schema of the ABAC rule blocks shared by the
+// lifeomic_policy resource and lifeomic_policy_document data source.
+func policyRuleBlock() tfsdk.Block {
+	return tfsdk.Block{
+		Description: fmt.Sprintf("An ABAC [rule](%s) containing comparisons to be evaluated for the given operation.", policyRuleDocsURL),
+		NestingMode: tfsdk.BlockNestingModeList,
+		Validators: []tfsdk.AttributeValidator{
+			&policyRulesValidator{},
+		},
+		Attributes: map[string]tfsdk.Attribute{
+			"operation": {
+				Type:     types.StringType,
+				Required: true,
+				Description: fmt.Sprintf("The [operation](%s) this ABAC rule governs."+
+					"Exactly one of `comparison` and `allowed` should be set.", policyOperationDocsURL),
+			},
+			"allowed": {
+				Type:        types.BoolType,
+				Optional:    true,
+				Description: "A describing whether this operation is allowed. Must either be null or true.",
+			},
 		},
 		Blocks: map[string]tfsdk.Block{
-			"rule": {
-				Description: fmt.Sprintf("An ABAC [rule](%s) containing comparisons to be evaluated for the given operation.", policyRuleDocsURL),
+			"comparison": {
+				Description: "An ABAC comparison. Exactly one of `value`, `values`, or `target` should be set, " +
+					"except for `exists` comparisons which set none of them. " +
+					"`in`, `notIn`, `superset`, and `subset` comparisons require `values` or `target`, " +
+					"and other comparisons require `value` or `target`.",
 				NestingMode: tfsdk.BlockNestingModeList,
 				Validators: []tfsdk.AttributeValidator{
-					&policyRulesValidator{},
+					&policyRuleComparisonValidator{},
 				},
-				MinItems: 1,
 				Attributes: map[string]tfsdk.Attribute{
-					"operation": {
-						Type:     types.StringType,
-						Required: true,
-						Description: fmt.Sprintf("The [operation](%s) this ABAC rule governs."+
-							"Exactly one of `comparison` and `allowed` should be set.", policyOperationDocsURL),
+					"type": {
+						Type:        types.StringType,
+						Required:    true,
+						Description: fmt.Sprintf("The [type](%s) of ABAC comparison.", policySupportedComparisonsDocsURL),
+					},
+					"subject": {
+						Type:        types.StringType,
+						Required:    true,
+						Description: fmt.Sprintf("The subject is the [attribute](%s) used in this ABAC comparison.", policyAttributeDocsURL),
 					},
-					"allowed": {
-						Type:        types.BoolType,
+					"values": {
+						Type:        types.ListType{ElemType: types.StringType},
 						Optional:    true,
-						Description: "A describing whether this operation is allowed. Must either be null or true.",
+						Description: "The values to use in this ABAC comparison. Must not be empty.",
 					},
-				},
-				Blocks: map[string]tfsdk.Block{
-					"comparison": {
-						Description: "An ABAC comparison. Exactly one of `value`, `values`, or `target` should be set.",
-						NestingMode: tfsdk.BlockNestingModeList,
-						Validators: []tfsdk.AttributeValidator{
-							&policyRuleComparisonValidator{},
-						},
-						Attributes: map[string]tfsdk.Attribute{
-							"type": {
-								Type:        types.StringType,
-								Required:    true,
-								Description: fmt.Sprintf("The [type](%s) of ABAC comparison.", policySupportedComparisonsDocsURL),
-							},
-							"subject": {
-								Type:        types.StringType,
-								Required:    true,
-								Description: fmt.Sprintf("The subject is the [attribute](%s) used in this ABAC comparison.", policyAttributeDocsURL),
-							},
-							"values": {
-								Type:        types.ListType{ElemType: types.StringType},
-								Optional:    true,
-								Description: "The values to use in this ABAC comparison.",
-							},
-							"value": {
-								Type:        types.StringType,
-								Optional:    true,
-								Description: "The value to use in this ABAC comparison.",
-							},
-							"target": {
-								Type:        types.StringType,
-								Optional:    true,
-								Description: "The target to use in this ABAC comparison.",
-							},
-						},
+					"value": {
+						Type:        types.StringType,
+						Optional:    true,
+						Description: "The value to use in this ABAC comparison.",
+					},
+					"target": {
+						Type:        types.StringType,
+						Optional:    true,
+						Description: "The target to use in this ABAC comparison.",
 					},
 				},
 			},
 		},
-	}, nil
+	}
 }
 
 func (policyResourceType) NewResource(_ context.Context, p tfsdk.Provider) (tfsdk.Resource, diag.Diagnostics) {
@@ -182,6 +244,31 @@ func (policyResourceType) NewResource(_ context.Context, p tfsdk.Provider) (tfsd
 	}, nil
 }
 
+// ValidateConfig ensures exactly one of the lifeomic_policy.rule blocks and
+// lifeomic_policy.policy_json is used to define the policy document.
+func (r policyResource) ValidateConfig(ctx context.Context, req tfsdk.ValidateResourceConfigRequest, resp *tfsdk.ValidateResourceConfigResponse) {
+	var rules types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("rule"), &rules)...)
+
+	var policyJSON types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("policy_json"), &policyJSON)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Dynamic blocks and references may not be known until apply-time.
+	if rules.Unknown || policyJSON.Unknown {
+		return
+	}
+
+	hasRules := !rules.Null && len(rules.Elems) != 0
+	if hasRules == !policyJSON.Null {
+		resp.Diagnostics.AddAttributeError(path.Root("policy_json"),
+			"Exactly one of rule and policy_json should be set",
+			"Either define the policy with rule blocks or provide a JSON encoded policy document with policy_json")
+	}
+}
+
 // walkPolicyRuleList attempts to cast the given tfsdk.List as a list of
 // policyRule structs and visits each element, calling the policyRuleWalkFunc.
 //
@@ -238,10 +325,26 @@ func (p *policy) ToPolicyObject(ctx context.Context) (policy *client.Policy, dia
 	policy = new(client.Policy)
 	policy.Name = p.Name.Value
 
-	// Build client.RuleMappings from resource struct.
-	rules := make(client.PolicyRules, len(p.Rule))
+	if !p.PolicyJSON.Null {
+		document, err := parsePolicyJSON(p.PolicyJSON.Value)
+		if err != nil {
+			diags.AddAttributeError(path.Root("policy_json"), "Invalid policy document", err.Error())
+			return
+		}
+		policy.Policy = *document
+		return
+	}
 
-	walkPolicyRuleList(ctx, path.Root("rule"), p.Rule, func(index int, rule *policyRule) {
+	policy.Policy.Rules = policyRulesFromBlocks(ctx, path.Root("rule"), p.Rule)
+	return
+}
+
+// policyRulesFromBlocks converts ABAC rule blocks to client.PolicyRules.
+func policyRulesFromBlocks(ctx context.Context, basePath path.Path, blocks []policyRule) client.PolicyRules {
+	// Build client.RuleMappings from rule blocks.
+	rules := make(client.PolicyRules, len(blocks))
+
+	walkPolicyRuleList(ctx, basePath, blocks, func(index int, rule *policyRule) {
 		operation := rule.Operation.Value
 
 		if rule.Allowed != nil {
@@ -274,11 +377,16 @@ func (p *policy) ToPolicyObject(ctx context.Context) (policy *client.Policy, dia
 					Target:     *comparisonSpec.Target,
 				}
 
-			case comparisonSpec.Values != nil && len(*comparisonSpec.Values) != 0:
+			case comparisonSpec.Values != nil:
 				comparison = client.MultivalueComparison{
 					Comparison: comparisonType,
 					Values:     *comparisonSpec.Values,
 				}
+
+			default:
+				comparison = client.ExistsComparison{
+					Comparison: comparisonType,
+				}
 			}
 
 			ruleMappings[i] = client.RuleMap{
@@ -290,8 +398,7 @@ func (p *policy) ToPolicyObject(ctx context.Context) (policy *client.Policy, dia
 		rules[operation] = ruleMappings
 	})
 
-	policy.Policy.Rules = rules
-	return
+	return rules
 }
 
 type terraformDescriptionNoop struct{}
@@ -332,18 +439,58 @@ func (v *policyRulesValidator) Validate(ctx context.Context, req tfsdk.ValidateA
 		return
 	}
 
-	resp.Diagnostics.Append(walkPolicyRuleList(ctx, req.AttributePath, list, func(_ int, _ *policyRule) {
-		// Noop. Nothing to process, walkPolicyRuleList will validate
-		// the rule blocks.
+	resp.Diagnostics.Append(walkPolicyRuleList(ctx, req.AttributePath, list, func(index int, rule *policyRule) {
+		// walkPolicyRuleList validates the structure of the rule
+		// blocks, only the operation names are left to check.
+		if rule.Operation.Unknown {
+			return
+		}
+		resp.Diagnostics.Append(validatePolicyOperation(req.AttributePath.AtListIndex(index).AtName("operation"), rule.Operation.Value)...)
 	})...)
 }
 
+// policyJSONValidator is a tfsdk.AttributeValidator for the
+// lifeomic_policy.policy_json attribute.
+type policyJSONValidator struct {
+	terraformDescriptionNoop
+}
+
+// Validate ensures that lifeomic_policy.policy_json is a well-formed ABAC
+// policy document.
+func (v *policyJSONValidator) Validate(ctx context.Context, req tfsdk.ValidateAttributeRequest, resp *tfsdk.ValidateAttributeResponse) {
+	var document types.String
+	resp.Diagnostics.Append(tfsdk.ValueAs(ctx, req.AttributeConfig, &document)...)
+	if resp.Diagnostics.HasError() || document.Unknown || document.Null {
+		return
+	}
+
+	parsed, err := parsePolicyJSON(document.Value)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(req.AttributePath, "Invalid policy document", err.Error())
+		return
+	}
+
+	for operation, rule := range parsed.Rules {
+		resp.Diagnostics.Append(validatePolicyOperation(req.AttributePath, operation)...)
+
+		ruleMappings, ok := rule.(client.RuleMappings)
+		if !ok {
+			continue
+		}
+		for _, ruleMap := range ruleMappings {
+			for _, comparison := range ruleMap {
+				resp.Diagnostics.Append(validateComparisonType(req.AttributePath, string(comparison.GetComparisonType()))...)
+			}
+		}
+	}
+}
+
 type policyRuleComparisonValidator struct {
 	terraformDescriptionNoop
 }
 
-// Validate enures that the lifeomic_policy.rule[*].comparison blocks only specify
-// one of the target, value, or values fields.
+// Validate enures that the lifeomic_policy.rule[*].comparison blocks are
+// valid. See validatePolicyRuleComparison.
 func (v *policyRuleComparisonValidator) Validate(ctx context.Context, req tfsdk.ValidateAttributeRequest, resp *tfsdk.ValidateAttributeResponse) {
 	if req.AttributeConfig.IsUnknown() {
 		return
@@ -356,27 +503,166 @@ func (v *policyRuleComparisonValidator) Validate(ctx context.Context, req tfsdk.
 	}
 
 	for i, comparison := range comparisons {
-		set := make([]string, 0, 3)
-		if comparison.Target != nil {
-			set = append(set, "target")
+		resp.Diagnostics.Append(validatePolicyRuleComparison(req.AttributePath.AtListIndex(i), comparison)...)
+	}
+}
+
+// validatePolicyRuleComparison returns an error if comparison doesn't use a
+// supported comparison type or doesn't specify exactly one of the target,
+// value, or values fields. exists comparisons must not specify any of them,
+// comparisons against sets of values must not use value, other comparisons
+// must not use values, and values must not be empty.
+func validatePolicyRuleComparison(attributePath path.Path, comparison policyRuleComparison) (diags diag.Diagnostics) {
+	set := make([]string, 0, 3)
+	if comparison.Target != nil {
+		set = append(set, "target")
+	}
+	if comparison.Value != nil {
+		set = append(set, "value")
+	}
+	if comparison.Values != nil {
+		set = append(set, "values")
+	}
+
+	if comparison.Type.Unknown {
+		return
+	}
+
+	comparisonType := client.ComparisonType(comparison.Type.Value)
+	diags.Append(validateComparisonType(attributePath.AtName("type"), comparison.Type.Value)...)
+
+	switch {
+	case diags.HasError():
+		return
+
+	case comparisonType == client.ComparisonExists:
+		if len(set) != 0 {
+			diags.AddAttributeError(attributePath,
+				"exists comparisons must not set value, values, or target",
+				fmt.Sprintf("Unset %s", set))
+		}
+
+	case len(set) != 1:
+		diags.AddAttributeError(attributePath,
+			"Exactly one of value, values, or target must be set",
+			fmt.Sprintf("Unset one of %s", set))
+
+	case comparisonType.IsMultivalued() && set[0] == "value":
+		diags.AddAttributeError(attributePath.AtName("value"),
+			fmt.Sprintf("%s comparisons require values or target", comparisonType),
+			fmt.Sprintf("Replace value with values = [%q]", *comparison.Value))
+
+	case !comparisonType.IsMultivalued() && set[0] == "values":
+		multivalued := make([]string, 0, len(client.ComparisonTypes))
+		for _, c := range client.ComparisonTypes {
+			if c.IsMultivalued() {
+				multivalued = append(multivalued, string(c))
+			}
 		}
-		if comparison.Value != nil {
-			set = append(set, "value")
+		diags.AddAttributeError(attributePath.AtName("values"),
+			fmt.Sprintf("%s comparisons require value or target", comparisonType),
+			fmt.Sprintf("Replace values with value, or use one of the %s comparison types. See %s",
+				multivalued, policySupportedComparisonsDocsURL))
+
+	case set[0] == "values" && len(*comparison.Values) == 0:
+		diags.AddAttributeError(attributePath.AtName("values"),
+			"values must not be empty",
+			"Set at least one value, or use an exists comparison to check the subject is set")
+	}
+	return
+}
+
+// validateComparisonType returns an error if comparisonType isn't a supported
+// ABAC comparison type, suggesting the closest supported type.
+func validateComparisonType(attributePath path.Path, comparisonType string) (diags diag.Diagnostics) {
+	if client.ComparisonType(comparisonType).IsSupported() {
+		return
+	}
+
+	supported := make([]string, len(client.ComparisonTypes))
+	for i, c := range client.ComparisonTypes {
+		supported[i] = string(c)
+	}
+
+	detail := fmt.Sprintf("Supported comparison types are %s. See %s", supported, policySupportedComparisonsDocsURL)
+	if suggestion, ok := closestMatch(comparisonType, supported); ok {
+		detail = fmt.Sprintf("Did you mean %q? %s", suggestion, detail)
+	}
+
+	diags.AddAttributeError(attributePath, fmt.Sprintf("Unsupported comparison type %q", comparisonType), detail)
+	return
+}
+
+// validatePolicyOperation returns a warning if operation isn't a well-known
+// ABAC operation, suggesting the closest known operation. It's not an error
+// as new operations may be introduced by the PHC API at any time.
+func validatePolicyOperation(attributePath path.Path, operation string) (diags diag.Diagnostics) {
+	for _, known := range client.Operations {
+		if operation == known {
+			return
 		}
-		if comparison.Values != nil {
-			set = append(set, "values")
+	}
+
+	detail := fmt.Sprintf("Known operations are %s. See %s", client.Operations, policyOperationDocsURL)
+	if suggestion, ok := closestMatch(operation, client.Operations); ok {
+		detail = fmt.Sprintf("Did you mean %q? %s", suggestion, detail)
+	}
+
+	diags.AddAttributeWarning(attributePath, fmt.Sprintf("Unknown operation %q", operation), detail)
+	return
+}
+
+// closestMatch returns the candidate with the smallest edit distance to value
+// if it's close enough to be a likely typo.
+func closestMatch(value string, candidates []string) (string, bool) {
+	best, bestDistance := "", -1
+	for _, candidate := range candidates {
+		distance := editDistance(strings.ToLower(value), strings.ToLower(candidate))
+		if bestDistance == -1 || distance < bestDistance {
+			best, bestDistance = candidate, distance
 		}
+	}
+
+	// Allow roughly one edit for every three characters.
+	return best, bestDistance != -1 && bestDistance <= len(value)/3+1
+}
+
+// editDistance computes the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
 
-		if len(set) != 1 {
-			resp.Diagnostics.AddAttributeError(req.AttributePath.AtListIndex(i),
-				"Exactly one of value, values, or target must be set",
-				fmt.Sprintf("Unset one of %s", set))
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			current[j] = previous[j] + 1
+			if insertion := current[j-1] + 1; insertion < current[j] {
+				current[j] = insertion
+			}
+			if substitution := previous[j-1] + cost; substitution < current[j] {
+				current[j] = substitution
+			}
 		}
+		previous, current = current, previous
 	}
+
+	return previous[len(rb)]
 }
 
 func (r policyResource) Create(ctx context.Context, req tfsdk.CreateResourceRequest, resp *tfsdk.CreateResourceResponse) {
 	tflog.Info(ctx, "Creating Policy resource")
+	ctx, span := startOperation(ctx, "lifeomic_policy", "create")
+	defer func() { endOperation(span, resp.Diagnostics) }()
 
 	// Get plan values.
 	var plan policy
@@ -392,10 +678,21 @@ func (r policyResource) Create(ctx context.Context, req tfsdk.CreateResourceRequ
 		return
 	}
 
+	clientSet, diags := r.clientSet.forResource(ctx, plan.AccountID)
+	if diags.HasError() {
+		resp.Diagnostics.Append(diags...)
+		return
+	}
+
 	// Create the policy.
-	p, err := r.clientSet.Policies.Create(ctx, p)
-	if err != nil {
-		resp.Diagnostics.AddError("failed to create policy", err.Error())
+	p, err := clientSet.Policies.Create(ctx, p)
+	if client.IsConflict(err) {
+		resp.Diagnostics.AddAttributeError(path.Root("name"), "Policy already exists",
+			fmt.Sprintf("A policy named %q already exists in the account. Choose another name or delete the existing policy.\n\n%s",
+				plan.Name.Value, err))
+		return
+	} else if err != nil {
+		resp.Diagnostics.Append(apiErrorDiagnostic("failed to create policy", err))
 		return
 	}
 
@@ -405,6 +702,8 @@ func (r policyResource) Create(ctx context.Context, req tfsdk.CreateResourceRequ
 
 func (r policyResource) Read(ctx context.Context, req tfsdk.ReadResourceRequest, resp *tfsdk.ReadResourceResponse) {
 	tflog.Info(ctx, "Reading Policy resource")
+	ctx, span := startOperation(ctx, "lifeomic_policy", "read")
+	defer func() { endOperation(span, resp.Diagnostics) }()
 
 	// Get current state.
 	var state policy
@@ -413,10 +712,22 @@ func (r policyResource) Read(ctx context.Context, req tfsdk.ReadResourceRequest,
 		return
 	}
 
+	clientSet, diags := r.clientSet.forResource(ctx, state.AccountID)
+	if diags.HasError() {
+		resp.Diagnostics.Append(diags...)
+		return
+	}
+
 	// Get the underlying Policy object.
-	p, err := r.clientSet.Policies.Get(ctx, state.Name.Value)
-	if err != nil {
-		resp.Diagnostics.AddError("failed to get policy", err.Error())
+	p, err := clientSet.Policies.Get(ctx, state.Name.Value)
+	if client.IsNotFound(err) {
+		// The policy was deleted outside of Terraform, so it needs to be
+		// created again.
+		tflog.Warn(ctx, "Policy not found, removing it from state", map[string]any{"name": state.Name.Value})
+		resp.State.RemoveResource(ctx)
+		return
+	} else if err != nil {
+		resp.Diagnostics.Append(apiErrorDiagnostic("failed to get policy", err))
 		return
 	}
 
@@ -426,6 +737,8 @@ func (r policyResource) Read(ctx context.Context, req tfsdk.ReadResourceRequest,
 
 func (r policyResource) Update(ctx context.Context, req tfsdk.UpdateResourceRequest, resp *tfsdk.UpdateResourceResponse) {
 	tflog.Info(ctx, "Updating Policy resource")
+	ctx, span := startOperation(ctx, "lifeomic_policy", "update")
+	defer func() { endOperation(span, resp.Diagnostics) }()
 
 	// Get plan values.
 	var plan policy
@@ -447,9 +760,15 @@ func (r policyResource) Update(ctx context.Context, req tfsdk.UpdateResourceRequ
 		return
 	}
 
-	p, err := r.clientSet.Policies.Update(ctx, state.Name.Value, p)
+	clientSet, diags := r.clientSet.forResource(ctx, state.AccountID)
+	if diags.HasError() {
+		resp.Diagnostics.Append(diags...)
+		return
+	}
+
+	p, err := clientSet.Policies.Update(ctx, state.Name.Value, p)
 	if err != nil {
-		resp.Diagnostics.AddError("failed to create policy", err.Error())
+		resp.Diagnostics.Append(apiErrorDiagnostic("failed to update policy", err))
 		return
 	}
 
@@ -459,6 +778,8 @@ func (r policyResource) Update(ctx context.Context, req tfsdk.UpdateResourceRequ
 
 func (r policyResource) Delete(ctx context.Context, req tfsdk.DeleteResourceRequest, resp *tfsdk.DeleteResourceResponse) {
 	tflog.Info(ctx, "Deleting Policy resource")
+	ctx, span := startOperation(ctx, "lifeomic_policy", "delete")
+	defer func() { endOperation(span, resp.Diagnostics) }()
 
 	// Get current state.
 	var state policy
@@ -467,14 +788,35 @@ func (r policyResource) Delete(ctx context.Context, req tfsdk.DeleteResourceRequ
 		return
 	}
 
-	if err := r.clientSet.Policies.Delete(ctx, state.Name.Value); err != nil {
-		resp.Diagnostics.AddError("failed to delete Policy", err.Error())
+	clientSet, diags := r.clientSet.forResource(ctx, state.AccountID)
+	if diags.HasError() {
+		resp.Diagnostics.Append(diags...)
+		return
+	}
+
+	// Policies which were already deleted outside of Terraform are simply
+	// removed from the state.
+	if err := clientSet.Policies.Delete(ctx, state.Name.Value); err != nil && !client.IsNotFound(err) {
+		resp.Diagnostics.Append(apiErrorDiagnostic("failed to delete Policy", err))
+		return
 	}
 
 	resp.State.RemoveResource(ctx)
 	tflog.Info(ctx, "Deleted existing Policy", map[string]any{"name": state.Name})
 }
 
+// ImportState imports a policy by its name, or by <account_id>/<name> for
+// policies in accounts other than the provider's.
+func (r policyResource) ImportState(ctx context.Context, req tfsdk.ImportResourceStateRequest, resp *tfsdk.ImportResourceStateResponse) {
+	name := req.ID
+	if accountID, id, ok := strings.Cut(req.ID, "/"); ok {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("account_id"), accountID)...)
+		name = id
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), name)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), name)...)
+}
+
 // flattenRuleMappings flattens a RuleMappings into a slice of
 // policyRuleComparison resources.
 func flattenRuleMappings(ruleMappings client.RuleMappings) []policyRuleComparison {
@@ -504,27 +846,104 @@ func flattenRuleMappings(ruleMappings client.RuleMappings) []policyRuleCompariso
 	return comparisons
 }
 
+// flattenPolicyRule builds the policyRule block of an operation's rule
+// expression.
+func flattenPolicyRule(operation string, expression client.RuleExpression) policyRule {
+	rule := policyRule{Operation: types.String{Value: operation}}
+	switch expression := expression.(type) {
+	case client.StaticRule:
+		allowed := bool(expression)
+		rule.Allowed = &allowed
+
+	case client.RuleMappings:
+		rule.Comparison = flattenRuleMappings(expression)
+	}
+	return rule
+}
+
+// parsePolicyJSON parses a JSON encoded ABAC policy document.
+func parsePolicyJSON(document string) (*client.PolicyDocument, error) {
+	var raw struct {
+		Rules *client.PolicyRules `json:"rules"`
+	}
+	if err := json.Unmarshal([]byte(document), &raw); err != nil {
+		return nil, err
+	}
+	if raw.Rules == nil {
+		return nil, fmt.Errorf("policy document must have a %q object", "rules")
+	}
+	return &client.PolicyDocument{Rules: *raw.Rules}, nil
+}
+
+// normalizePolicyJSON returns the canonical JSON encoding of an ABAC policy
+// document. Documents which only differ in key order or whitespace normalize
+// to the same value.
+func normalizePolicyJSON(document string) (string, error) {
+	parsed, err := parsePolicyJSON(document)
+	if err != nil {
+		return "", err
+	}
+
+	normalized, err := json.Marshal(parsed)
+	if err != nil {
+		return "", err
+	}
+	return string(normalized), nil
+}
+
+// setPolicyJSONState sets the state of a lifeomic_policy resource defined by
+// policy_json. The configured document is kept as-is if it's semantically
+// equal to the remote policy to avoid spurious diffs.
+func setPolicyJSONState(ctx context.Context, config *policy, state *tfsdk.State, p *client.Policy) (diags diag.Diagnostics) {
+	remote, err := json.Marshal(p.Policy)
+	if err != nil {
+		diags.AddError("failed to encode policy document", err.Error())
+		return
+	}
+
+	policyJSON := string(remote)
+	if configured, err := normalizePolicyJSON(config.PolicyJSON.Value); err == nil && configured == policyJSON {
+		policyJSON = config.PolicyJSON.Value
+	}
+
+	diags.Append(state.Set(ctx, policy{
+		ID:         types.String{Value: p.Name},
+		AccountID:  config.AccountID,
+		Name:       types.String{Value: p.Name},
+		PolicyJSON: types.String{Value: policyJSON},
+		Rule:       []policyRule{},
+	})...)
+	return
+}
+
 func setPolicyState(ctx context.Context, config *policy, state *tfsdk.State, p *client.Policy) (diags diag.Diagnostics) {
+	if !config.PolicyJSON.Null {
+		return setPolicyJSONState(ctx, config, state, p)
+	}
+
 	rules := make([]policyRule, 0, len(p.Policy.Rules))
 
+	// Imported policies have no rule blocks to follow yet, so all of the
+	// remote rules are set, ordered by operation.
+	if len(config.Rule) == 0 {
+		operations := make([]string, 0, len(p.Policy.Rules))
+		for operation := range p.Policy.Rules {
+			operations = append(operations, operation)
+		}
+		sort.Strings(operations)
+
+		for _, operation := range operations {
+			rules = append(rules, flattenPolicyRule(operation, p.Policy.Rules[operation]))
+		}
+		p.Policy.Rules = nil
+	}
+
 	// Set the rules in the same order as they are declared in the plan
 	// generated from the user's config to avoid messy diffs.
 	// UnmarshalJSON does not order maps as they are parsed.
 	walkPolicyRuleList(ctx, path.Root("rule"), config.Rule, func(index int, ruleSpec *policyRule) {
 		operation := ruleSpec.Operation.Value
-		ruleElem := policyRule{Operation: types.String{Value: operation}}
-
-		// Build policyRule resource from this rule.
-		switch expression := p.Policy.Rules[operation].(type) {
-		case client.StaticRule:
-			allowed := bool(expression)
-			ruleElem.Allowed = &allowed
-
-		case client.RuleMappings:
-			ruleElem.Comparison = flattenRuleMappings(expression)
-		}
-
-		rules = append(rules, ruleElem)
+		rules = append(rules, flattenPolicyRule(operation, p.Policy.Rules[operation]))
 
 		// Removed processed rule block from the policy object to keep
 		// track of remaining rules.
@@ -545,9 +964,11 @@ func setPolicyState(ctx context.Context, config *policy, state *tfsdk.State, p *
 	}
 
 	diags.Append(state.Set(ctx, policy{
-		ID:   types.String{Value: p.Name},
-		Name: types.String{Value: p.Name},
-		Rule: rules,
+		ID:         types.String{Value: p.Name},
+		AccountID:  config.AccountID,
+		Name:       types.String{Value: p.Name},
+		PolicyJSON: types.String{Null: true},
+		Rule:       rules,
 	})...)
 	return
 }
@@ -4,10 +4,17 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"strings"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/client"
+	"github.com/stretchr/testify/assert"
 )
 
 var testPolicyResName = "lifeomic_policy.test"
@@ -138,6 +145,29 @@ func TestAccPHCPolicy_update(t *testing.T) {
 	})
 }
 
+func TestAccPHCPolicy_import(t *testing.T) {
+	t.Parallel()
+	name := randomResourceName(t, 8)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 testAccPreCheck(t),
+		ProtoV6ProviderFactories: testAccProviderFactories,
+
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPHCPolicy_staticRule(name),
+				Check:  checkPolicyExists,
+			},
+			{
+				ResourceName:      testPolicyResName,
+				ImportState:       true,
+				ImportStateId:     name,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
 func TestAccPHCPolicy_duplicateRuleBlock(t *testing.T) {
 	t.Parallel()
 	name := randomResourceName(t, 8)
@@ -206,8 +236,309 @@ func TestAccPHCPolicy_conflictingComparisonFields(t *testing.T) {
 	})
 }
 
+func TestAccPHCPolicy_json(t *testing.T) {
+	t.Parallel()
+	name := randomResourceName(t, 8)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 testAccPreCheck(t),
+		ProtoV6ProviderFactories: testAccProviderFactories,
+
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPHCPolicy_json(name, `{"rules": {"readData": [{"user.groups": {"comparison": "includes", "value": "admin"}}]}}`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					checkPolicyExists,
+					resource.TestCheckResourceAttr(testPolicyResName, "name", name),
+					resource.TestCheckResourceAttr(testPolicyResName, "rule.#", "0"),
+				),
+			},
+			{
+				// Reordering keys should not produce a diff.
+				Config:             testAccPHCPolicy_json(name, `{"rules": {"readData": [{"user.groups": {"value": "admin", "comparison": "includes"}}]}}`),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: false,
+			},
+			{
+				// Neither should reformatting the document.
+				Config:             testAccPHCPolicy_json(name, "{\n  \"rules\": {\n    \"readData\": [{\"user.groups\": {\"value\": \"admin\", \"comparison\": \"includes\"}}]\n  }\n}"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: false,
+			},
+		},
+	})
+}
+
+func TestAccPHCPolicy_conflictingPolicyJSON(t *testing.T) {
+	t.Parallel()
+	name := randomResourceName(t, 8)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 testAccPreCheck(t),
+		ProtoV6ProviderFactories: testAccProviderFactories,
+
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccPHCPolicy_conflictingPolicyJSON(name),
+				ExpectError: regexp.MustCompile("Exactly one of rule and policy_json should be set"),
+			},
+		},
+	})
+}
+
+func TestAccPHCPolicy_invalidPolicyJSON(t *testing.T) {
+	t.Parallel()
+	name := randomResourceName(t, 8)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 testAccPreCheck(t),
+		ProtoV6ProviderFactories: testAccProviderFactories,
+
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccPHCPolicy_json(name, `{"rules": {"readData": [{"user.id": {"comparison": "equals"}}]}}`),
+				ExpectError: regexp.MustCompile("Invalid policy document"),
+			},
+		},
+	})
+}
+
+func TestAccPHCPolicy_invalidComparisonType(t *testing.T) {
+	t.Parallel()
+	name := randomResourceName(t, 8)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 testAccPreCheck(t),
+		ProtoV6ProviderFactories: testAccProviderFactories,
+
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccPHCPolicy_comparison(name, `type = "equal"`, `value = "bob"`),
+				ExpectError: regexp.MustCompile(`Did you mean "equals"\?`),
+			},
+		},
+	})
+}
+
+func TestAccPHCPolicy_invalidComparisonValue(t *testing.T) {
+	t.Parallel()
+	name := randomResourceName(t, 8)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 testAccPreCheck(t),
+		ProtoV6ProviderFactories: testAccProviderFactories,
+
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccPHCPolicy_comparison(name, `type = "exists"`, `value = "bob"`),
+				ExpectError: regexp.MustCompile("exists comparisons must not set value, values, or target"),
+			},
+			{
+				Config:      testAccPHCPolicy_comparison(name, `type = "superset"`, `value = "admin"`),
+				ExpectError: regexp.MustCompile("superset comparisons require values or target"),
+			},
+			{
+				Config:      testAccPHCPolicy_comparison(name, `type = "equals"`, `values = ["admin"]`),
+				ExpectError: regexp.MustCompile("equals comparisons require value or target"),
+			},
+			{
+				Config:      testAccPHCPolicy_comparison(name, `type = "in"`, `values = []`),
+				ExpectError: regexp.MustCompile("values must not be empty"),
+			},
+		},
+	})
+}
+
+func TestAccPHCPolicy_existsComparison(t *testing.T) {
+	t.Parallel()
+	name := randomResourceName(t, 8)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 testAccPreCheck(t),
+		ProtoV6ProviderFactories: testAccProviderFactories,
+
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPHCPolicy_comparison(name, `type = "exists"`, ""),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					checkPolicyExists,
+					resource.TestCheckResourceAttr(testPolicyResName, "rule.0.comparison.0.type", "exists"),
+					resource.TestCheckNoResourceAttr(testPolicyResName, "rule.0.comparison.0.value"),
+				),
+			},
+		},
+	})
+}
+
+func TestValidateComparisonType(t *testing.T) {
+	for _, fixture := range []struct {
+		comparisonType string
+		expectedDetail string
+	}{
+		{comparisonType: "equals"},
+		{comparisonType: "superset"},
+		{comparisonType: "equal", expectedDetail: `Did you mean "equals"?`},
+		{comparisonType: "startswith", expectedDetail: `Did you mean "startsWith"?`},
+		{comparisonType: "notIncluded", expectedDetail: `Did you mean "notIncludes"?`},
+		{comparisonType: "regex", expectedDetail: "Supported comparison types are"},
+	} {
+		t.Run(fixture.comparisonType, func(t *testing.T) {
+			diags := validateComparisonType(path.Root("type"), fixture.comparisonType)
+			if fixture.expectedDetail == "" {
+				assert.False(t, diags.HasError(), "unexpected error: %v", diags)
+				return
+			}
+
+			if assert.True(t, diags.HasError()) {
+				assert.True(t, strings.HasPrefix(diags[0].Detail(), fixture.expectedDetail), diags[0].Detail())
+			}
+		})
+	}
+}
+
+func TestValidatePolicyRuleComparison(t *testing.T) {
+	value := "admin"
+	values := []string{"admin", "doctor"}
+
+	for _, fixture := range []struct {
+		name            string
+		comparison      policyRuleComparison
+		expectedSummary string
+	}{
+		{
+			name:       "should allow a value",
+			comparison: policyRuleComparison{Type: types.String{Value: "equals"}, Value: &value},
+		},
+		{
+			name:       "should allow values for multivalued comparisons",
+			comparison: policyRuleComparison{Type: types.String{Value: "in"}, Values: &values},
+		},
+		{
+			name:       "should allow a target",
+			comparison: policyRuleComparison{Type: types.String{Value: "superset"}, Target: &value},
+		},
+		{
+			name:       "should allow exists comparisons without a value",
+			comparison: policyRuleComparison{Type: types.String{Value: "exists"}},
+		},
+		{
+			name:       "should skip unknown types",
+			comparison: policyRuleComparison{Type: types.String{Unknown: true}, Values: &[]string{}},
+		},
+		{
+			name:            "should reject unsupported types",
+			comparison:      policyRuleComparison{Type: types.String{Value: "equal"}, Value: &value},
+			expectedSummary: `Unsupported comparison type "equal"`,
+		},
+		{
+			name:            "should reject values for exists comparisons",
+			comparison:      policyRuleComparison{Type: types.String{Value: "exists"}, Values: &values},
+			expectedSummary: "exists comparisons must not set value, values, or target",
+		},
+		{
+			name:            "should reject both value and values",
+			comparison:      policyRuleComparison{Type: types.String{Value: "in"}, Value: &value, Values: &values},
+			expectedSummary: "Exactly one of value, values, or target must be set",
+		},
+		{
+			name:            "should reject a missing value",
+			comparison:      policyRuleComparison{Type: types.String{Value: "equals"}},
+			expectedSummary: "Exactly one of value, values, or target must be set",
+		},
+		{
+			name:            "should reject a value for multivalued comparisons",
+			comparison:      policyRuleComparison{Type: types.String{Value: "subset"}, Value: &value},
+			expectedSummary: "subset comparisons require values or target",
+		},
+		{
+			name:            "should reject values for single valued comparisons",
+			comparison:      policyRuleComparison{Type: types.String{Value: "includes"}, Values: &values},
+			expectedSummary: "includes comparisons require value or target",
+		},
+		{
+			name:            "should reject empty values",
+			comparison:      policyRuleComparison{Type: types.String{Value: "notIn"}, Values: &[]string{}},
+			expectedSummary: "values must not be empty",
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			diags := validatePolicyRuleComparison(path.Root("comparison").AtListIndex(0), fixture.comparison)
+			if fixture.expectedSummary == "" {
+				assert.False(t, diags.HasError(), "unexpected error: %v", diags)
+				return
+			}
+
+			if assert.True(t, diags.HasError()) {
+				assert.Equal(t, fixture.expectedSummary, diags[0].Summary())
+			}
+		})
+	}
+}
+
+func TestValidatePolicyOperation(t *testing.T) {
+	diags := validatePolicyOperation(path.Root("operation"), "readData")
+	assert.Empty(t, diags)
+
+	diags = validatePolicyOperation(path.Root("operation"), "raedData")
+	assert.False(t, diags.HasError())
+	if assert.Len(t, diags, 1) {
+		assert.Equal(t, `Unknown operation "raedData"`, diags[0].Summary())
+		assert.True(t, strings.HasPrefix(diags[0].Detail(), `Did you mean "readData"?`))
+	}
+}
+
+func TestNormalizePolicyJSON(t *testing.T) {
+	for _, fixture := range []struct {
+		name          string
+		json          string
+		expectedValue string
+		expectedErr   string
+	}{
+		{
+			name:          "should sort operations and comparison keys",
+			json:          `{"rules": {"writeData": true, "readData": [{"user.id": {"value": "bob", "comparison": "equals"}}]}}`,
+			expectedValue: `{"rules":{"readData":[{"user.id":{"comparison":"equals","value":"bob"}}],"writeData":true}}`,
+		},
+		{
+			name:          "should preserve comparison order",
+			json:          `{"rules": {"readData": [{"user.id": {"comparison": "equals", "value": "bob"}}, {"user.groups": {"comparison": "superset", "value": ["admin"]}}]}}`,
+			expectedValue: `{"rules":{"readData":[{"user.id":{"comparison":"equals","value":"bob"}},{"user.groups":{"comparison":"superset","value":["admin"]}}]}}`,
+		},
+		{
+			name:        "should require rules",
+			json:        `{"readData": true}`,
+			expectedErr: `policy document must have a "rules" object`,
+		},
+		{
+			name:        "should reject malformed rule maps",
+			json:        `{"rules": {"readData": [{"user.id": {"comparison": "equals", "value": "bob"}, "user.name": {"comparison": "equals", "value": "bob"}}]}}`,
+			expectedErr: "should have exactly one entry",
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			value, err := normalizePolicyJSON(fixture.json)
+			if err != nil {
+				if fixture.expectedErr == "" {
+					t.Errorf("unexpected error: %s", err)
+					return
+				}
+				assert.ErrorContains(t, err, fixture.expectedErr)
+			} else if fixture.expectedErr != "" {
+				t.Errorf("expected error matching: %s; got nil", fixture.expectedErr)
+				return
+			}
+
+			assert.Equal(t, fixture.expectedValue, value)
+		})
+	}
+}
+
 func checkPolicyExists(s *terraform.State) error {
-	policyClient := newClientSet("", "", nil).Policies
+	clientSet, err := newClientSet(client.Config{})
+	if err != nil {
+		return err
+	}
+	policyClient := clientSet.Policies
 
 	for _, res := range s.RootModule().Resources {
 		if res.Type != "lifeomic_policy" {
@@ -368,3 +699,130 @@ func testAccPHCPolicy_manyRules(name string) string {
   }
 }`, name)
 }
+
+func testAccPHCPolicy_json(name, document string) string {
+	return fmt.Sprintf(`resource "lifeomic_policy" "test" {
+  name        = "%s"
+  policy_json = %q
+}`, name, document)
+}
+
+func testAccPHCPolicy_conflictingPolicyJSON(name string) string {
+	return fmt.Sprintf(`resource "lifeomic_policy" "test" {
+  name        = "%s"
+  policy_json = jsonencode({ rules = { readData = true } })
+
+  rule {
+    operation = "readData"
+    allowed   = true
+  }
+}`, name)
+}
+
+func testAccPHCPolicy_comparison(name, comparisonType, value string) string {
+	return fmt.Sprintf(`resource "lifeomic_policy" "test" {
+  name = "%s"
+
+  rule {
+    operation = "readData"
+
+    comparison {
+      subject = "user.groups"
+      %s
+      %s
+    }
+  }
+}`, name, comparisonType, value)
+}
+
+func TestPolicyJSONPlanModifier(t *testing.T) {
+	const state = `{"rules": {"readData": [{"user.groups": {"comparison": "includes", "value": "admin"}}]}}`
+
+	for _, fixture := range []struct {
+		name          string
+		config        string
+		expectedValue string
+	}{
+		{
+			name:          "should keep the state when keys are reordered",
+			config:        `{"rules": {"readData": [{"user.groups": {"value": "admin", "comparison": "includes"}}]}}`,
+			expectedValue: state,
+		},
+		{
+			name:          "should keep the state when whitespace changes",
+			config:        "{\n  \"rules\": {\"readData\": [{\"user.groups\": {\"comparison\": \"includes\", \"value\": \"admin\"}}]}\n}",
+			expectedValue: state,
+		},
+		{
+			name:          "should plan documents which changed",
+			config:        `{"rules": {"readData": true}}`,
+			expectedValue: `{"rules": {"readData": true}}`,
+		},
+		{
+			name:          "should plan null for policies defined by rules",
+			expectedValue: "",
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			config := types.String{Value: fixture.config, Null: fixture.config == ""}
+			req := tfsdk.ModifyAttributePlanRequest{
+				AttributePath:   path.Root("policy_json"),
+				AttributeConfig: config,
+				AttributePlan:   types.String{Value: fixture.config, Unknown: fixture.config == ""},
+				AttributeState:  types.String{Value: state},
+			}
+			resp := &tfsdk.ModifyAttributePlanResponse{AttributePlan: req.AttributePlan}
+
+			(&policyJSONPlanModifier{}).Modify(context.Background(), req, resp)
+			assert.False(t, resp.Diagnostics.HasError())
+			assert.Equal(t, types.String{Value: fixture.expectedValue, Null: fixture.expectedValue == ""}, resp.AttributePlan)
+		})
+	}
+}
+
+func TestSetPolicyState_imported(t *testing.T) {
+	ctx := context.Background()
+	schema, diags := policyResourceType{}.GetSchema(ctx)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	state := tfsdk.State{Schema: schema, Raw: tftypes.NewValue(schema.TerraformType(ctx), nil)}
+
+	imported := policy{
+		ID:         types.String{Value: "test"},
+		AccountID:  types.String{Value: "other-account"},
+		Name:       types.String{Value: "test"},
+		PolicyJSON: types.String{Null: true},
+	}
+	p := &client.Policy{Name: "test", Policy: client.PolicyDocument{Rules: client.PolicyRules{
+		"writeData": client.StaticRule(true),
+		"readData": client.RuleMappings{
+			{"user.groups": &client.ValueComparison{Comparison: client.ComparisonIncludes, Value: "admin"}},
+		},
+	}}}
+
+	diags = setPolicyState(ctx, &imported, &state, p)
+	assert.Empty(t, diags, "should set every rule without drift warnings")
+
+	var value policy
+	assert.Empty(t, state.Get(ctx, &value))
+
+	allowed, admin := true, "admin"
+	assert.Equal(t, policy{
+		ID:         types.String{Value: "test"},
+		AccountID:  types.String{Value: "other-account"},
+		Name:       types.String{Value: "test"},
+		PolicyJSON: types.String{Null: true},
+		Rule: []policyRule{
+			{
+				Operation: types.String{Value: "readData"},
+				Comparison: []policyRuleComparison{{
+					Type:    types.String{Value: "includes"},
+					Subject: types.String{Value: "user.groups"},
+					Value:   &admin,
+				}},
+			},
+			{Operation: types.String{Value: "writeData"}, Allowed: &allowed},
+		},
+	}, value)
+}
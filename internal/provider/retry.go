@@ -0,0 +1,124 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/client"
+)
+
+// providerRetry represents the provider's retry block.
+type providerRetry struct {
+	MaxRetries types.Int64  `tfsdk:"max_retries"`
+	MinWait    types.String `tfsdk:"min_wait"`
+	MaxWait    types.String `tfsdk:"max_wait"`
+}
+
+func retryBlock() tfsdk.Block {
+	return tfsdk.Block{
+		Description: "Configure how requests to the PHC API are retried. Requests which failed with a network error, " +
+			"a 429, or a 5xx response are retried with exponential backoff, or after the delay requested by the " +
+			"`Retry-After` header. Only idempotent requests, such as GraphQL queries, are retried.",
+		NestingMode: tfsdk.BlockNestingModeList,
+		MaxItems:    1,
+		Attributes: map[string]tfsdk.Attribute{
+			"max_retries": {
+				Type:     types.Int64Type,
+				Optional: true,
+				Description: fmt.Sprintf("The number of times a request is retried. `0` disables retries. Defaults to `%d`.",
+					client.Config{}.RetryPolicy().MaxRetries),
+			},
+			"min_wait": {
+				Type:     types.StringType,
+				Optional: true,
+				Description: fmt.Sprintf("How long to wait before the first retry, as a duration such as `250ms`. "+
+					"The wait time doubles with every retry. Defaults to `%s`.", client.Config{}.RetryPolicy().WaitTime),
+				Validators: []tfsdk.AttributeValidator{
+					&durationValidator{},
+				},
+			},
+			"max_wait": {
+				Type:     types.StringType,
+				Optional: true,
+				Description: fmt.Sprintf("The longest time to wait between retries, as a duration such as `10s`. "+
+					"Defaults to `%s`.", client.Config{}.RetryPolicy().MaxWaitTime),
+				Validators: []tfsdk.AttributeValidator{
+					&durationValidator{},
+				},
+			},
+		},
+	}
+}
+
+// applyRetryBlock sets the retry policy of config from the provider's retry
+// block.
+func applyRetryBlock(config *client.Config, retry []providerRetry) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if len(retry) == 0 {
+		return diags
+	}
+	blockPath := path.Root("retry").AtListIndex(0)
+
+	switch maxRetries := retry[0].MaxRetries; {
+	case maxRetries.Null:
+	case maxRetries.Value < 0:
+		diags.AddAttributeError(blockPath.AtName("max_retries"), "Invalid max_retries",
+			"max_retries must not be negative")
+	case maxRetries.Value == 0:
+		config.MaxRetries = -1
+	default:
+		config.MaxRetries = int(maxRetries.Value)
+	}
+
+	var err error
+	if !retry[0].MinWait.Null {
+		if config.RetryWaitTime, err = parsePositiveDuration(retry[0].MinWait.Value); err != nil {
+			diags.AddAttributeError(blockPath.AtName("min_wait"), "Invalid duration", err.Error())
+		}
+	}
+	if !retry[0].MaxWait.Null {
+		if config.MaxRetryWaitTime, err = parsePositiveDuration(retry[0].MaxWait.Value); err != nil {
+			diags.AddAttributeError(blockPath.AtName("max_wait"), "Invalid duration", err.Error())
+		}
+	}
+	if config.RetryWaitTime > 0 && config.MaxRetryWaitTime > 0 && config.MaxRetryWaitTime < config.RetryWaitTime {
+		diags.AddAttributeError(blockPath.AtName("max_wait"), "Invalid duration",
+			"max_wait must not be shorter than min_wait")
+	}
+	return diags
+}
+
+// parsePositiveDuration parses a duration such as "500ms", which must be
+// greater than zero.
+func parsePositiveDuration(value string) (time.Duration, error) {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if duration <= 0 {
+		return 0, fmt.Errorf("duration %q must be greater than zero", value)
+	}
+	return duration, nil
+}
+
+type durationValidator struct {
+	terraformDescriptionNoop
+}
+
+// Validate ensures that a string attribute is a positive duration.
+func (v *durationValidator) Validate(ctx context.Context, req tfsdk.ValidateAttributeRequest, resp *tfsdk.ValidateAttributeResponse) {
+	var value types.String
+	resp.Diagnostics.Append(tfsdk.ValueAs(ctx, req.AttributeConfig, &value)...)
+	if resp.Diagnostics.HasError() || value.Unknown || value.Null {
+		return
+	}
+
+	if _, err := parsePositiveDuration(value.Value); err != nil {
+		resp.Diagnostics.AddAttributeError(req.AttributePath, "Invalid duration", err.Error())
+	}
+}
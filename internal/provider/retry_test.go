@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyRetryBlock(t *testing.T) {
+	for _, fixture := range []struct {
+		name          string
+		retry         []providerRetry
+		expectedValue client.Config
+		expectedErr   string
+	}{
+		{
+			name: "should use the client defaults without a retry block",
+		},
+		{
+			name: "should set the retry policy",
+			retry: []providerRetry{{
+				MaxRetries: types.Int64{Value: 5},
+				MinWait:    types.String{Value: "250ms"},
+				MaxWait:    types.String{Value: "10s"},
+			}},
+			expectedValue: client.Config{MaxRetries: 5, RetryWaitTime: 250 * time.Millisecond, MaxRetryWaitTime: 10 * time.Second},
+		},
+		{
+			name: "should disable retries",
+			retry: []providerRetry{{
+				MaxRetries: types.Int64{Value: 0},
+				MinWait:    types.String{Null: true},
+				MaxWait:    types.String{Null: true},
+			}},
+			expectedValue: client.Config{MaxRetries: -1},
+		},
+		{
+			name: "should reject negative durations",
+			retry: []providerRetry{{
+				MaxRetries: types.Int64{Null: true},
+				MinWait:    types.String{Value: "-1s"},
+				MaxWait:    types.String{Null: true},
+			}},
+			expectedErr: "must be greater than zero",
+		},
+		{
+			name: "should reject a max_wait shorter than min_wait",
+			retry: []providerRetry{{
+				MaxRetries: types.Int64{Null: true},
+				MinWait:    types.String{Value: "1s"},
+				MaxWait:    types.String{Value: "500ms"},
+			}},
+			expectedErr: "max_wait must not be shorter than min_wait",
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			var config client.Config
+			diags := applyRetryBlock(&config, fixture.retry)
+			if fixture.expectedErr != "" {
+				if assert.True(t, diags.HasError()) {
+					assert.Contains(t, diags[0].Detail(), fixture.expectedErr)
+				}
+				return
+			}
+			assert.False(t, diags.HasError())
+			assert.Equal(t, fixture.expectedValue, config)
+		})
+	}
+}
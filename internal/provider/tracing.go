@@ -0,0 +1,32 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/client"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/tracing"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startOperation starts the span of an operation on a resource or data source,
+// e.g. startOperation(ctx, "lifeomic_policy", "create"). The spans of its API
+// calls are children of it, and they're logged to the http subsystem it sets
+// up.
+func startOperation(ctx context.Context, typeName, operation string) (context.Context, trace.Span) {
+	ctx = client.WithLogSubsystem(ctx)
+	return tracing.StartSpan(ctx, typeName+"."+operation, trace.WithAttributes(
+		tracing.ResourceTypeKey.String(typeName),
+		tracing.OperationKey.String(operation),
+	))
+}
+
+// endOperation ends the span of an operation, marking it as failed if its
+// diagnostics have errors.
+func endOperation(span trace.Span, diags diag.Diagnostics) {
+	if errs := diags.Errors(); len(errs) != 0 {
+		span.SetStatus(codes.Error, errs[0].Summary())
+	}
+	span.End()
+}
@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/client"
+)
+
+// newHTTPTransport creates the HTTP transport shared by the provider's clients
+// from its TLS, proxy, and connect_timeout attributes. The request_timeout
+// attribute is returned as well, or zero if it isn't set.
+func newHTTPTransport(config *providerData) (*http.Transport, time.Duration, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	transportConfig := client.TransportConfig{
+		CABundle:          config.CABundleFile.Value,
+		ClientCertificate: config.ClientCertificateFile.Value,
+		ClientKey:         config.ClientKeyFile.Value,
+		Proxy:             config.ProxyURL.Value,
+	}
+
+	if config.ProxyURL.Value != "" {
+		if proxy, err := url.Parse(config.ProxyURL.Value); err != nil || proxy.Scheme == "" || proxy.Host == "" {
+			diags.AddAttributeError(path.Root("proxy_url"), "Invalid proxy URL",
+				"proxy_url must be an absolute URL such as http://proxy.example.com:3128")
+		}
+	}
+	if (config.ClientCertificateFile.Value == "") != (config.ClientKeyFile.Value == "") {
+		diags.AddAttributeError(path.Root("client_key_file"), "Incomplete client certificate",
+			"Both \"client_certificate_file\" and \"client_key_file\" must be set to authenticate with a client certificate")
+	}
+
+	var err error
+	if !config.ConnectTimeout.Null {
+		if transportConfig.ConnectTimeout, err = parsePositiveDuration(config.ConnectTimeout.Value); err != nil {
+			diags.AddAttributeError(path.Root("connect_timeout"), "Invalid duration", err.Error())
+		}
+	}
+	var requestTimeout time.Duration
+	if !config.RequestTimeout.Null {
+		if requestTimeout, err = parsePositiveDuration(config.RequestTimeout.Value); err != nil {
+			diags.AddAttributeError(path.Root("request_timeout"), "Invalid duration", err.Error())
+		}
+	}
+	if diags.HasError() {
+		return nil, 0, diags
+	}
+
+	transport, err := client.NewHTTPTransport(transportConfig)
+	if err != nil {
+		diags.AddError("Unable to configure the HTTP transport", err.Error())
+		return nil, 0, diags
+	}
+	return transport, requestTimeout, diags
+}
@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHTTPTransport(t *testing.T) {
+	for _, fixture := range []struct {
+		name            string
+		config          providerData
+		expectedTimeout time.Duration
+		expectedErr     string
+	}{
+		{
+			name: "should configure the transport",
+			config: providerData{
+				ProxyURL:       types.String{Value: "http://proxy.example.com:3128"},
+				ConnectTimeout: types.String{Value: "5s"},
+				RequestTimeout: types.String{Value: "1m"},
+			},
+			expectedTimeout: time.Minute,
+		},
+		{
+			name:        "should reject relative proxy URLs",
+			config:      providerData{ProxyURL: types.String{Value: "proxy.example.com"}, ConnectTimeout: types.String{Null: true}, RequestTimeout: types.String{Null: true}},
+			expectedErr: "Invalid proxy URL",
+		},
+		{
+			name:        "should require a client key",
+			config:      providerData{ClientCertificateFile: types.String{Value: "client.pem"}, ConnectTimeout: types.String{Null: true}, RequestTimeout: types.String{Null: true}},
+			expectedErr: "Incomplete client certificate",
+		},
+		{
+			name:        "should reject invalid timeouts",
+			config:      providerData{ConnectTimeout: types.String{Null: true}, RequestTimeout: types.String{Value: "soon"}},
+			expectedErr: "Invalid duration",
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			transport, timeout, diags := newHTTPTransport(&fixture.config)
+			if fixture.expectedErr != "" {
+				if assert.True(t, diags.HasError()) {
+					assert.Equal(t, fixture.expectedErr, diags[0].Summary())
+				}
+				return
+			}
+			assert.False(t, diags.HasError())
+			assert.NotNil(t, transport)
+			assert.Equal(t, fixture.expectedTimeout, timeout)
+		})
+	}
+}
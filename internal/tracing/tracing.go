@@ -0,0 +1,118 @@
+// Package tracing traces the provider's operations and API calls with
+// OpenTelemetry. Tracing is off by default, and enabled by the standard OTEL_*
+// environment variables, e.g. $OTEL_EXPORTER_OTLP_ENDPOINT.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// TracerName is the name of the provider's tracer.
+	TracerName = "github.com/lifeomic/terraform-provider-lifeomic"
+
+	serviceName = "terraform-provider-lifeomic"
+)
+
+// Attribute keys of the provider's spans.
+const (
+	ResourceTypeKey     = attribute.Key("lifeomic.resource_type")
+	OperationKey        = attribute.Key("lifeomic.operation")
+	AccountIDKey        = attribute.Key("lifeomic.account_id")
+	ServiceKey          = attribute.Key("lifeomic.service")
+	GraphQLOperationKey = attribute.Key("graphql.operation.name")
+)
+
+// envParent is the span in $TRACEPARENT, which spans without a parent are
+// children of.
+var envParent trace.SpanContext
+
+// Enabled reports whether the OTEL_* environment variables enable tracing.
+// Spans are exported with OTLP over HTTP, so tracing is enabled when
+// $OTEL_TRACES_EXPORTER is otlp, or an OTLP endpoint is set.
+func Enabled() bool {
+	if disabled, _ := strconv.ParseBool(os.Getenv("OTEL_SDK_DISABLED")); disabled {
+		return false
+	}
+	switch os.Getenv("OTEL_TRACES_EXPORTER") {
+	case "otlp":
+		return true
+	case "":
+		return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+	}
+	return false
+}
+
+// Start sets up the global tracer provider and W3C trace context propagation
+// if tracing is enabled. The returned func flushes and stops exporting spans,
+// and must be called before the provider exits.
+func Start(ctx context.Context, version string) (shutdown func(context.Context) error, err error) {
+	if !Enabled() {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+
+	// Resource attributes from $OTEL_SERVICE_NAME and
+	// $OTEL_RESOURCE_ATTRIBUTES take precedence over the defaults.
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceNameKey.String(serviceName), semconv.ServiceVersionKey.String(version)),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	envParent = trace.SpanContextFromContext(otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier{
+		"traceparent": os.Getenv("TRACEPARENT"),
+		"tracestate":  os.Getenv("TRACESTATE"),
+	}))
+	return provider.Shutdown, nil
+}
+
+// StartSpan starts a span with the provider's tracer. Spans without a parent
+// are children of the span in $TRACEPARENT, if it's set, so that they join
+// the trace of the pipeline running Terraform.
+func StartSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() && envParent.IsValid() {
+		ctx = trace.ContextWithRemoteSpanContext(ctx, envParent)
+	}
+	return otel.Tracer(TracerName).Start(ctx, name, opts...)
+}
+
+// Inject adds the span of ctx to header as a W3C traceparent, so that the
+// spans of the services it's sent to join the trace.
+func Inject(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// EndSpan ends span, marking it as failed if err isn't nil.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
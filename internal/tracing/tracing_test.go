@@ -0,0 +1,64 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestEnabled(t *testing.T) {
+	for _, fixture := range []struct {
+		name          string
+		env           map[string]string
+		expectedValue bool
+	}{
+		{
+			name:          "should be off by default",
+			expectedValue: false,
+		},
+		{
+			name:          "should be enabled by an OTLP endpoint",
+			env:           map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://localhost:4318"},
+			expectedValue: true,
+		},
+		{
+			name:          "should be enabled by the OTLP exporter",
+			env:           map[string]string{"OTEL_TRACES_EXPORTER": "otlp"},
+			expectedValue: true,
+		},
+		{
+			name:          "should be disabled by other exporters",
+			env:           map[string]string{"OTEL_TRACES_EXPORTER": "none", "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT": "http://localhost:4318"},
+			expectedValue: false,
+		},
+		{
+			name:          "should be disabled by OTEL_SDK_DISABLED",
+			env:           map[string]string{"OTEL_SDK_DISABLED": "true", "OTEL_TRACES_EXPORTER": "otlp"},
+			expectedValue: false,
+		},
+	} {
+		t.Run(fixture.name, func(t *testing.T) {
+			for _, name := range []string{"OTEL_SDK_DISABLED", "OTEL_TRACES_EXPORTER", "OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"} {
+				t.Setenv(name, fixture.env[name])
+			}
+			assert.Equal(t, fixture.expectedValue, Enabled())
+		})
+	}
+}
+
+func TestStartSpan(t *testing.T) {
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	envParent = parent
+	t.Cleanup(func() { envParent = trace.SpanContext{} })
+
+	ctx, span := StartSpan(context.Background(), "test")
+	defer span.End()
+	assert.Equal(t, parent, trace.SpanContextFromContext(ctx), "should use the span from $TRACEPARENT as the parent")
+}
@@ -6,7 +6,9 @@ import (
 	"log"
 
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/client"
 	"github.com/lifeomic/terraform-provider-lifeomic/internal/provider"
+	"github.com/lifeomic/terraform-provider-lifeomic/internal/tracing"
 )
 
 //go:generate go run github.com/hashicorp/terraform-plugin-docs/cmd/tfplugindocs
@@ -22,7 +24,16 @@ func main() {
 		Debug:   debugMode,
 	}
 
-	err := providerserver.Serve(context.Background(), provider.New, opts)
+	ctx := context.Background()
+	shutdownTracing, err := tracing.Start(ctx, client.GitRef)
+	if err != nil {
+		log.Fatalf("failed to initialize tracing: %v\n", err)
+	}
+
+	err = providerserver.Serve(ctx, provider.New, opts)
+	if shutdownErr := shutdownTracing(ctx); shutdownErr != nil {
+		log.Printf("failed to export traces: %v\n", shutdownErr)
+	}
 
 	if err != nil {
 		log.Fatalf("failed to initialize provider: %v\n", err)